@@ -0,0 +1,122 @@
+package main
+
+import (
+	"envtemplate/lib"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkDirectoryOptions validates the options used when rendering a whole directory tree
+func checkDirectoryOptions(cf commandlineFlags) error {
+	if len(cf.InputFile) > 0 || len(cf.OutputFile) > 0 {
+		return fmt.Errorf("input-dir/output-dir cannot be used together with in/out")
+	}
+	if len(cf.InputDir) == 0 || len(cf.OutputDir) == 0 {
+		return fmt.Errorf("both input-dir and output-dir must be set to render a directory")
+	}
+	if info, err := os.Stat(cf.InputDir); err != nil {
+		return fmt.Errorf("cannot read input directory %s: %v", cf.InputDir, err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("input directory %s is not a directory", cf.InputDir)
+	}
+
+	inputDir, err := filepath.Abs(cf.InputDir)
+	if err != nil {
+		return err
+	}
+	outputDir, err := filepath.Abs(cf.OutputDir)
+	if err != nil {
+		return err
+	}
+	// Otherwise we would end up rendering our own output
+	if rel, err := filepath.Rel(inputDir, outputDir); err == nil && filepath.IsLocal(rel) {
+		return fmt.Errorf("output directory %s cannot be inside the input directory %s", cf.OutputDir, cf.InputDir)
+	}
+	return nil
+}
+
+// renderDirectory mirrors the cf.InputDir tree on cf.OutputDir, keeping the relative paths and
+// permissions of every file. If cf.TemplateSuffix is set, only the files that end with it are
+// rendered (and written without the suffix); the rest are copied unchanged. Otherwise every file
+// is rendered. All the templates are evaluated against the same data.
+func renderDirectory(cf commandlineFlags, data lib.TemplateData) error {
+	return filepath.WalkDir(cf.InputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(cf.InputDir, path)
+		if err != nil {
+			return err
+		}
+		outPath := filepath.Join(cf.OutputDir, relPath)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(outPath, info.Mode().Perm()|0o700)
+		case !info.Mode().IsRegular():
+			_, _ = fmt.Fprintf(os.Stderr, "Skipping %s: not a regular file\n", path)
+			return nil
+		case len(cf.TemplateSuffix) > 0 && !strings.HasSuffix(relPath, cf.TemplateSuffix):
+			return copyFile(path, outPath, info.Mode().Perm())
+		default:
+			return renderFile(cf, path, strings.TrimSuffix(outPath, cf.TemplateSuffix), info.Mode().Perm(), data)
+		}
+	})
+}
+
+// renderFile evaluates the template stored on inPath against data, and writes the result to outPath
+func renderFile(cf commandlineFlags, inPath, outPath string, perm fs.FileMode, data lib.TemplateData) (err error) {
+	tmplData, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("cannot read template %s: %v", inPath, err)
+	}
+	tmplt, err := newTemplate(inPath, outPath, tmplData, cf)
+	if err != nil {
+		return fmt.Errorf("%s: %v", inPath, err)
+	}
+
+	out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("cannot open output file %s: %v", outPath, err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	if err = tmplt.Execute(out, data); err != nil {
+		return fmt.Errorf("error generating %s: %v", outPath, err)
+	}
+	return nil
+}
+
+// copyFile copies inPath into outPath without any processing
+func copyFile(inPath, outPath string, perm fs.FileMode) (err error) {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("cannot open output file %s: %v", outPath, err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"envtemplate/lib"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDirectoryOptions(t *testing.T) {
+	base := t.TempDir()
+	inputDir := filepath.Join(base, "in")
+	if err := os.Mkdir(inputDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	inputFile := filepath.Join(base, "file.txt")
+	if err := os.WriteFile(inputFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cf      commandlineFlags
+		wantErr string
+	}{
+		{
+			name: "Valid directories",
+			cf:   commandlineFlags{InputDir: inputDir, OutputDir: filepath.Join(base, "out")},
+		},
+		{
+			name:    "Output directory inside the input directory",
+			cf:      commandlineFlags{InputDir: inputDir, OutputDir: filepath.Join(inputDir, "out")},
+			wantErr: "cannot be inside the input directory",
+		},
+		{
+			name:    "Output directory is the input directory",
+			cf:      commandlineFlags{InputDir: inputDir, OutputDir: inputDir + "/."},
+			wantErr: "cannot be inside the input directory",
+		},
+		{
+			name:    "Missing output directory",
+			cf:      commandlineFlags{InputDir: inputDir},
+			wantErr: "both input-dir and output-dir must be set",
+		},
+		{
+			name:    "Mixed with input file",
+			cf:      commandlineFlags{InputDir: inputDir, OutputDir: filepath.Join(base, "out"), InputFile: inputFile},
+			wantErr: "cannot be used together with in/out",
+		},
+		{
+			name:    "Input directory is a file",
+			cf:      commandlineFlags{InputDir: inputFile, OutputDir: filepath.Join(base, "out")},
+			wantErr: "is not a directory",
+		},
+		{
+			name:    "Input directory does not exist",
+			cf:      commandlineFlags{InputDir: filepath.Join(base, "missing"), OutputDir: filepath.Join(base, "out")},
+			wantErr: "cannot read input directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDirectoryOptions(tt.cf)
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkDirectoryOptions() error = %v, wantErr %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderDirectory(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix string
+		files  map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "Suffix stripped and other files copied",
+			suffix: ".tmpl",
+			files: map[string]string{
+				"app.conf.tmpl":     "name={[.NAME]}",
+				"static.conf":       "name={[.NAME]}",
+				"sub/nested.tmpl":   "nested {[.NAME]}",
+				"sub/deep/raw.json": `{"a": 1}`,
+			},
+			want: map[string]string{
+				"app.conf":          "name=service",
+				"static.conf":       "name={[.NAME]}",
+				"sub/nested":        "nested service",
+				"sub/deep/raw.json": `{"a": 1}`,
+			},
+		},
+		{
+			name: "Every file rendered without suffix",
+			files: map[string]string{
+				"app.conf":     "name={[.NAME]}",
+				"sub/app.tmpl": "{[.NAME]}",
+			},
+			want: map[string]string{
+				"app.conf":     "name=service",
+				"sub/app.tmpl": "service",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			cf := commandlineFlags{
+				InputDir:       filepath.Join(base, "in"),
+				OutputDir:      filepath.Join(base, "out"),
+				TemplateSuffix: tt.suffix,
+				LeftDelim:      "{[",
+				RightDelim:     "]}",
+			}
+			for name, content := range tt.files {
+				path := filepath.Join(cf.InputDir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o640); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := renderDirectory(cf, lib.TemplateData{"NAME": "service"}); err != nil {
+				t.Fatalf("renderDirectory() error = %v", err)
+			}
+
+			got := map[string]string{}
+			err := filepath.WalkDir(cf.OutputDir, func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(cf.OutputDir, path)
+				got[filepath.ToSlash(rel)] = string(content)
+				if info, err := d.Info(); err == nil && info.Mode().Perm() != 0o640 {
+					t.Errorf("%s has mode %v, want %v", rel, info.Mode().Perm(), os.FileMode(0o640))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("renderDirectory() wrote %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %q, want %q", name, got[name], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	templateUtils "envtemplate/template"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// escapeModes maps each escaping mode to the function (from templateUtils.FuncMap) it applies
+var escapeModes = map[string]string{
+	"sh":      "shellQuote",
+	"env":     "dotenvQuote",
+	"systemd": "systemdEscape",
+	"xml":     "xmlEscape",
+}
+
+// escapeModeForPath returns the escaping mode that auto-escape uses for an output file, based on
+// its extension, or an empty string if the output is not escaped
+func escapeModeForPath(outputName string) string {
+	switch strings.ToLower(filepath.Ext(outputName)) {
+	case ".sh":
+		return "sh"
+	case ".env":
+		return "env"
+	case ".xml":
+		return "xml"
+	}
+	return ""
+}
+
+// escapeTemplate makes every action of tmplt (and of the templates it defines) that outputs
+// something pass its value through the escaper for mode, as html/template does. Actions that
+// already end with an escaper are left as they are, so they are not escaped twice
+func escapeTemplate(tmplt *template.Template, mode string) error {
+	if mode == "" || mode == "none" {
+		return nil
+	}
+	escaper, ok := escapeModes[mode]
+	if !ok {
+		return fmt.Errorf("unknown escape mode %s", mode)
+	}
+	for _, t := range tmplt.Templates() {
+		if t.Tree != nil {
+			escapeNode(t.Tree, t.Tree.Root, escaper)
+		}
+	}
+	return nil
+}
+
+func escapeNode(tree *parse.Tree, node parse.Node, escaper string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeNode(tree, child, escaper)
+		}
+	case *parse.ActionNode:
+		// Actions that declare or assign variables do not output anything
+		if len(n.Pipe.Decl) > 0 || endsWithEscaper(n.Pipe) {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(escaper).SetTree(tree).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeNode(tree, n.List, escaper)
+		escapeNode(tree, n.ElseList, escaper)
+	case *parse.RangeNode:
+		escapeNode(tree, n.List, escaper)
+		escapeNode(tree, n.ElseList, escaper)
+	case *parse.WithNode:
+		escapeNode(tree, n.List, escaper)
+		escapeNode(tree, n.ElseList, escaper)
+	}
+}
+
+// endsWithEscaper returns true if the last command of pipe is one of the escaping functions or
+// methods, as in {[.VAR | shellQuote]} or {[.VAR.ShellQuote]}
+func endsWithEscaper(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) == 0 {
+		return false
+	}
+	cmd := pipe.Cmds[len(pipe.Cmds)-1]
+	if len(cmd.Args) == 0 {
+		return false
+	}
+	var name string
+	switch arg := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		name = arg.Ident
+	case *parse.FieldNode:
+		name = arg.Ident[len(arg.Ident)-1]
+	case *parse.ChainNode:
+		name = arg.Field[len(arg.Field)-1]
+	case *parse.VariableNode:
+		name = arg.Ident[len(arg.Ident)-1]
+	}
+	for function, method := range templateUtils.Escapers {
+		if name == function || name == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"envtemplate/lib"
+	"strings"
+	"testing"
+)
+
+func TestEscapeModeForPath(t *testing.T) {
+	tests := map[string]string{
+		"run.sh":          "sh",
+		"conf/app.ENV":    "env",
+		".env":            "env",
+		"pom.xml":         "xml",
+		"app.conf":        "",
+		"out.sh.bak":      "",
+		"":                "",
+		"dir.sh/settings": "",
+	}
+	for outputName, want := range tests {
+		if got := escapeModeForPath(outputName); got != want {
+			t.Errorf("escapeModeForPath(%q) = %q, want %q", outputName, got, want)
+		}
+	}
+}
+
+func TestAutoEscape(t *testing.T) {
+	tests := []struct {
+		name       string
+		tmpl       string
+		outputName string
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "Action",
+			tmpl:       "NAME={[.NAME]}",
+			outputName: "run.sh",
+			want:       `NAME='it'\''s $HOME'`,
+		},
+		{
+			name:       "Escaper function",
+			tmpl:       "NAME={[.NAME | shellQuote]}",
+			outputName: "run.sh",
+			want:       `NAME='it'\''s $HOME'`,
+		},
+		{
+			name:       "Escaper method",
+			tmpl:       "NAME={[.NAME.ShellQuote]}",
+			outputName: "run.sh",
+			want:       `NAME='it'\''s $HOME'`,
+		},
+		{
+			name:       "Other escaper",
+			tmpl:       "NAME={[.NAME | dotenvQuote]}",
+			outputName: "run.sh",
+			want:       `NAME="it's \$HOME"`,
+		},
+		{
+			name:       "Escaper before the last command",
+			tmpl:       "{[.NAME | shellQuote | printf \"%s!\"]}",
+			outputName: "run.sh",
+			want:       `''\''it'\''\'\'''\''s $HOME'\''!'`,
+		},
+		{
+			name:       "Declarations are not escaped",
+			tmpl:       "{[$name := .NAME]}{[$name = \"x\"]}{[$name]}",
+			outputName: "run.sh",
+			want:       `'x'`,
+		},
+		{
+			name:       "Control structures",
+			tmpl:       "{[if .NAME]}{[.NAME]}{[else]}-{[end]} {[range $k, $v := .Filter \"^NAME$\"]}{[$k]}={[$v]}{[end]} {[with .NAME]}{[.]}{[end]}",
+			outputName: "run.sh",
+			want:       `'it'\''s $HOME' 'NAME'='it'\''s $HOME' 'it'\''s $HOME'`,
+		},
+		{
+			name:       "Defined templates",
+			tmpl:       "{[define \"name\"]}{[.]}{[end]}{[template \"name\" .NAME]}",
+			outputName: "run.sh",
+			want:       `'it'\''s $HOME'`,
+		},
+		{
+			name:       "Output file extension",
+			tmpl:       "<name>{[.TAG]}</name>",
+			outputName: "pom.xml",
+			want:       "<name>&lt;b&gt; &amp; co</name>",
+		},
+		{
+			name:       "No escaping for stdout",
+			tmpl:       "NAME={[.NAME]}",
+			outputName: "",
+			want:       `NAME=it's $HOME`,
+		},
+		{
+			name:       "No escaping for other extensions",
+			tmpl:       "NAME={[.NAME]}",
+			outputName: "app.conf",
+			want:       `NAME=it's $HOME`,
+		},
+		{
+			name:       "Header sets the escaping",
+			tmpl:       "#envtemplate escape=systemd\nNAME={[.NAME]}",
+			outputName: "",
+			want:       `NAME="it's \$HOME"`,
+		},
+		{
+			name:       "Header overrides the extension",
+			tmpl:       "#envtemplate escape=env\nNAME={[.NAME]}",
+			outputName: "run.sh",
+			want:       `NAME="it's \$HOME"`,
+		},
+		{
+			name:       "Header disables the escaping",
+			tmpl:       "#envtemplate escape=none\nNAME={[.NAME]}",
+			outputName: "run.sh",
+			want:       `NAME=it's $HOME`,
+		},
+		{
+			name:       "Invalid escape mode",
+			tmpl:       "#envtemplate escape=html\nNAME={[.NAME]}",
+			outputName: "run.sh",
+			wantErr:    "escape",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := commandlineFlags{LeftDelim: "{[", RightDelim: "]}", AutoEscape: true}
+			tmplt, err := newTemplate("test.tmpl", tt.outputName, []byte(tt.tmpl), cf)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("newTemplate() error = %v, wantErr %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			err = tmplt.Execute(&out, lib.TemplateData{"NAME": "it's $HOME", "TAG": "<b> & co"})
+			if err != nil || out.String() != tt.want {
+				t.Errorf("Execute() = %q, %v, want %q", out.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoEscapeDisabled(t *testing.T) {
+	cf := commandlineFlags{LeftDelim: "{[", RightDelim: "]}"}
+	tmplt, err := newTemplate("test.tmpl", "run.sh", []byte("NAME={[.NAME]}"), cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := tmplt.Execute(&out, lib.TemplateData{"NAME": "it's"}); err != nil || out.String() != "NAME=it's" {
+		t.Errorf("Execute() = %q, %v, want %q", out.String(), err, "NAME=it's")
+	}
+}
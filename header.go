@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// headerPrefix marks the optional first line where a template can declare its own options, as in
+//
+//	#envtemplate delims="<% %>" escape=sh
+const headerPrefix = "#envtemplate"
+
+var headerOptionRexp = regexp.MustCompile(`^([\w-]+)=("(?:[^"\\]|\\.)*"|\S+)\s*`)
+
+// templateHeader holds the options that can be set on the template header. Any option that is not
+// present on the header keeps the value passed on the command line
+type templateHeader struct {
+	LeftDelim  string
+	RightDelim string
+	// Escape is the escaping mode applied to the output of every action (sh, env, systemd, xml or
+	// none). See escapeTemplate
+	Escape string
+}
+
+// parseHeader extracts the options from the header line of tmplData, if it has one. The header
+// line is replaced with an empty comment that spans the line, so the template does not output it
+// but the line numbers reported on errors still match the template file
+func parseHeader(tmplData []byte, defaults templateHeader) (header templateHeader, body []byte, err error) {
+	header, body = defaults, tmplData
+
+	firstLine, rest, hasRest := bytes.Cut(tmplData, []byte("\n"))
+	firstLine = bytes.TrimSuffix(firstLine, []byte("\r"))
+	options, isHeader := strings.CutPrefix(string(firstLine), headerPrefix)
+	if !isHeader || (len(options) > 0 && options[0] != ' ' && options[0] != '\t') {
+		return
+	}
+
+	options = strings.TrimSpace(options)
+	for len(options) > 0 {
+		match := headerOptionRexp.FindStringSubmatch(options)
+		if match == nil {
+			return header, body, fmt.Errorf("invalid template header option: %s", options)
+		}
+		options = options[len(match[0]):]
+		value := match[2]
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return header, body, fmt.Errorf("invalid value for template header option %s: %v", match[1], err)
+			}
+		}
+		switch match[1] {
+		case "delims":
+			delims := strings.Fields(value)
+			if len(delims) != 2 {
+				return header, body, fmt.Errorf("delims must be two space separated delimiters, got %q", value)
+			}
+			header.LeftDelim, header.RightDelim = delims[0], delims[1]
+		case "escape":
+			if _, known := escapeModes[value]; !known && value != "none" {
+				return header, body, fmt.Errorf("unknown escape mode %q", value)
+			}
+			header.Escape = value
+		default:
+			return header, body, fmt.Errorf("unknown template header option: %s", match[1])
+		}
+	}
+
+	body = []byte(header.LeftDelim + "/*\n*/" + header.RightDelim)
+	if hasRest {
+		body = append(body, rest...)
+	}
+	return
+}
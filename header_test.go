@@ -0,0 +1,122 @@
+package main
+
+import (
+	"envtemplate/lib"
+	"strings"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	defaults := templateHeader{LeftDelim: "{[", RightDelim: "]}"}
+	tests := []struct {
+		name     string
+		tmplData string
+		want     templateHeader
+		wantBody string
+		wantErr  string
+	}{
+		{
+			name:     "No header",
+			tmplData: "name={[.NAME]}\n",
+			want:     defaults,
+			wantBody: "name={[.NAME]}\n",
+		},
+		{
+			name:     "Delimiters",
+			tmplData: "#envtemplate delims=\"<% %>\"\nname=<% .NAME %>\n",
+			want:     templateHeader{LeftDelim: "<%", RightDelim: "%>"},
+			wantBody: "<%/*\n*/%>name=<% .NAME %>\n",
+		},
+		{
+			name:     "Header without a body",
+			tmplData: "#envtemplate delims=\"<% %>\"",
+			want:     templateHeader{LeftDelim: "<%", RightDelim: "%>"},
+			wantBody: "<%/*\n*/%>",
+		},
+		{
+			name:     "Windows line ending",
+			tmplData: "#envtemplate delims=\"{{ }}\"\r\n{{.NAME}}",
+			want:     templateHeader{LeftDelim: "{{", RightDelim: "}}"},
+			wantBody: "{{/*\n*/}}{{.NAME}}",
+		},
+		{
+			name:     "Empty header",
+			tmplData: "#envtemplate\nname",
+			want:     defaults,
+			wantBody: "{[/*\n*/]}name",
+		},
+		{
+			name:     "Escape mode",
+			tmplData: "#envtemplate escape=sh delims=\"<% %>\"\n",
+			want:     templateHeader{LeftDelim: "<%", RightDelim: "%>", Escape: "sh"},
+			wantBody: "<%/*\n*/%>",
+		},
+		{
+			name:     "Not a header",
+			tmplData: "#envtemplatex delims=\"<% %>\"\n",
+			want:     defaults,
+			wantBody: "#envtemplatex delims=\"<% %>\"\n",
+		},
+		{
+			name:     "Single delimiter",
+			tmplData: "#envtemplate delims=\"<%\"\n",
+			wantErr:  "delims must be two space separated delimiters",
+		},
+		{
+			name:     "Unquoted delimiters",
+			tmplData: "#envtemplate delims=<% %>\n",
+			wantErr:  "delims must be two space separated delimiters",
+		},
+		{
+			name:     "Unterminated quote",
+			tmplData: "#envtemplate delims=\"<% %>\n",
+			wantErr:  "invalid value for template header option delims",
+		},
+		{
+			name:     "Unknown option",
+			tmplData: "#envtemplate color=blue\n",
+			wantErr:  "unknown template header option: color",
+		},
+		{
+			name:     "Unknown escape mode",
+			tmplData: "#envtemplate escape=yaml\n",
+			wantErr:  `unknown escape mode "yaml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, body, err := parseHeader([]byte(tt.tmplData), defaults)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseHeader() error = %v, wantErr %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHeader() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseHeader() header = %+v, want %+v", got, tt.want)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("parseHeader() body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHeaderKeepsLineNumbers(t *testing.T) {
+	tmplData := "#envtemplate delims=\"<% %>\"\nline 2\n<% .MISSING.Bool %>\n"
+	tmplt, err := newTemplate("test.tmpl", "", []byte(tmplData), commandlineFlags{LeftDelim: "{[", RightDelim: "]}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	err = tmplt.Execute(&out, lib.TemplateData{"MISSING": "maybe"})
+	if err == nil || !strings.Contains(err.Error(), "test.tmpl:3:") {
+		t.Errorf("Execute() error = %v, want it on test.tmpl:3", err)
+	}
+	if strings.HasPrefix(out.String(), "#") || !strings.HasPrefix(out.String(), "line 2\n") {
+		t.Errorf("Execute() output = %q, want the header removed", out.String())
+	}
+}
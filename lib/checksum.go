@@ -0,0 +1,24 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"envtemplate/template"
+)
+
+// Checksum returns the SHA-256 hash (hex encoded) of the variables whose names match pattern. The
+// variables are hashed sorted by name, and both names and values are included, so the result only
+// changes if any of the matching variables does. This makes it useful to trigger restarts when a
+// configuration changes.
+func (t TemplateData) Checksum(pattern string) (template.ExtendedString, error) {
+	entries, err := t.filterEntries(pattern, func(a, b string) bool { return a < b })
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	for _, entry := range entries {
+		// Environment names and values cannot contain NUL, so this is not ambiguous
+		_, _ = hash.Write([]byte(entry.Key + "=" + string(entry.Value) + "\x00"))
+	}
+	return template.ExtendedString(hex.EncodeToString(hash.Sum(nil))), nil
+}
@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"envtemplate/template"
+	"testing"
+)
+
+func TestTemplateData_Checksum(t *testing.T) {
+	data := TemplateData{"APP_A": "1", "APP_B": "2", "OTHER": "x"}
+	got, err := data.Checksum("^APP_")
+	if err != nil {
+		t.Fatalf("Checksum() unexpected error = %v", err)
+	}
+	// sha256("APP_A=1\x00APP_B=2\x00")
+	if want := template.ExtendedString("469dfc13cdfb1e87dcbdf1c9cf8b8a104c4e65a5c20d920ce05bb0ceac19336f"); got != want {
+		t.Errorf("Checksum() = %v, want %v", got, want)
+	}
+	if again, _ := data.Checksum("^APP_"); again != got {
+		t.Errorf("Checksum() is not deterministic: %v != %v", again, got)
+	}
+	if other, _ := data.Checksum("^(APP_|OTHER)"); other == got {
+		t.Errorf("Checksum() should change when the variables do")
+	}
+	if changed, _ := (TemplateData{"APP_A": "1", "APP_B": "3"}).Checksum("^APP_"); changed == got {
+		t.Errorf("Checksum() should change when a value does")
+	}
+	if _, err := data.Checksum("(("); err == nil {
+		t.Errorf("Checksum() should fail with an invalid pattern")
+	}
+}
@@ -0,0 +1,18 @@
+package lib
+
+// dataSources holds the structured documents mounted with MountData, by name
+var dataSources = map[string]any{}
+
+// MountData makes value reachable from the templates as .Data.name. Mounting a second value with
+// the same name replaces the first one
+func MountData(name string, value any) {
+	dataSources[name] = value
+}
+
+// Data returns the structured documents mounted with MountData, by name. They're not part of t, so
+// any TemplateData (such as the ones returned by Filter) returns the same documents. Since methods
+// take precedence over map keys on templates, .Data always refers to this, even if there's an
+// environment variable called Data. That variable can still be read with {[index . "Data"]}
+func (t TemplateData) Data() map[string]any {
+	return dataSources
+}
@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateData_Data(t *testing.T) {
+	defer func() { dataSources = map[string]any{} }()
+	MountData("cluster", map[string]any{"servers": []any{"a", "b"}})
+	MountData("replaced", "old")
+	MountData("replaced", "new")
+
+	data := TemplateData{"Data": "variable", "REGION": "eu", "APP_NAME": "app"}
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "Mounted document",
+			tmpl: `{[range .Data.cluster.servers]}{[.]}-{[$.REGION]} {[end]}`,
+			want: "a-eu b-eu ",
+		},
+		{
+			name: "Mounting twice replaces the document",
+			tmpl: `{[.Data.replaced]}`,
+			want: "new",
+		},
+		{
+			name: "Data shadows a variable called Data",
+			tmpl: `{[.Data.replaced]} {[index . "Data"]}`,
+			want: "new variable",
+		},
+		{
+			name: "Data is also reachable from derived TemplateData",
+			tmpl: `{[with .Filter "^APP_"]}{[.Data.replaced]}{[end]}`,
+			want: "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmplt := template.Must(template.New(tt.name).Delims("{[", "]}").Parse(tt.tmpl))
+			var out strings.Builder
+			if err := tmplt.Execute(&out, data); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Execute() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// EnvVar is a single variable definition read from a dotenv file
+type EnvVar struct {
+	Name  string
+	Value string
+	// Literal is true if the value was single quoted, so nothing on it should ever be expanded. The
+	// references on the other values are already expanded by ParseDotenv
+	Literal bool
+}
+
+// ParseDotenv parses the dotenv formatted content read from reader, returning the variables in the
+// order they are defined. The supported syntax is:
+//
+//	# Comments, and blank lines, are ignored
+//	export NAME=value         # export prefixes are optional. Unquoted values are trimmed
+//	NAME='literal value'      # nothing is expanded inside single quotes
+//	NAME="line 1\nline 2"     # \n, \r, \t, \", \\ and \$ escapes are supported inside double quotes
+//	NAME="multi
+//	line"                     # double (and single) quoted values can span several lines
+//	NAME=${OTHER}/$OTHER      # references are expanded on unquoted and double quoted values
+//
+// References are resolved against the variables previously defined on the same file and, if not
+// defined there, using lookup (which can be nil). Undefined references expand to an empty string.
+// protected (which can be nil too) returns the values of the variables the file cannot override,
+// such as the environment ones when they take precedence: references to them resolve to that value
+// even if the file defines them, so they match the value that is actually used.
+// Since the $ references are already expanded (and single quoted values are literal), the values
+// should not go through ExpandVariables again, other than for their late (%NAME%) references (see
+// ExpandOptions). source is only used to build the error messages.
+func ParseDotenv(reader io.Reader, source string, lookup, protected func(string) (string, bool)) ([]EnvVar, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", source, err)
+	}
+	p := dotenvParser{
+		data:      strings.ReplaceAll(string(content), "\r\n", "\n"),
+		source:    source,
+		line:      1,
+		lookup:    lookup,
+		protected: protected,
+		defined:   map[string]string{},
+	}
+	return p.parse()
+}
+
+type dotenvParser struct {
+	data      string
+	pos       int
+	line      int
+	source    string
+	lookup    func(string) (string, bool)
+	protected func(string) (string, bool)
+	defined   map[string]string
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", p.source, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// skipBlanks skips spaces and tabs (but not new lines)
+func (p *dotenvParser) skipBlanks() {
+	for !p.eof() && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine skips everything up to, and including, the next new line
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+	if !p.eof() {
+		p.pos++
+		p.line++
+	}
+}
+
+// endOfLine checks that only blanks or a comment remain on the current line, and skips them
+func (p *dotenvParser) endOfLine() error {
+	p.skipBlanks()
+	if !p.eof() && p.data[p.pos] != '\n' && p.data[p.pos] != '#' {
+		return p.errorf("unexpected characters after value: %q", p.restOfLine())
+	}
+	p.skipLine()
+	return nil
+}
+
+func (p *dotenvParser) restOfLine() string {
+	end := strings.IndexByte(p.data[p.pos:], '\n')
+	if end < 0 {
+		return p.data[p.pos:]
+	}
+	return p.data[p.pos : p.pos+end]
+}
+
+func (p *dotenvParser) parse() (vars []EnvVar, err error) {
+	for !p.eof() {
+		p.skipBlanks()
+		if p.eof() {
+			break
+		}
+		if c := p.data[p.pos]; c == '\n' || c == '#' {
+			p.skipLine()
+			continue
+		}
+
+		if strings.HasPrefix(p.data[p.pos:], "export ") || strings.HasPrefix(p.data[p.pos:], "export\t") {
+			p.pos += len("export")
+			p.skipBlanks()
+		}
+		name := p.readName(true)
+		if len(name) == 0 {
+			return nil, p.errorf("invalid variable name: %q", p.restOfLine())
+		}
+		p.skipBlanks()
+		if p.eof() || p.data[p.pos] != '=' {
+			return nil, p.errorf("missing = after variable name %s", name)
+		}
+		p.pos++
+		p.skipBlanks()
+
+		var value string
+		literal := false
+		switch {
+		case p.eof():
+		case p.data[p.pos] == '\'':
+			value, err = p.readSingleQuoted()
+			literal = true
+		case p.data[p.pos] == '"':
+			value, err = p.readDoubleQuoted()
+		default:
+			value = p.readUnquoted()
+		}
+		if err == nil {
+			err = p.endOfLine()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.defined[name] = value
+		vars = append(vars, EnvVar{Name: name, Value: value, Literal: literal})
+	}
+	return vars, nil
+}
+
+// readName reads a variable name. Definitions (but not references) can also use dots and dashes
+func (p *dotenvParser) readName(definition bool) string {
+	start := p.pos
+	for !p.eof() && isNameChar(rune(p.data[p.pos]), p.pos == start, definition) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func isNameChar(c rune, first, definition bool) bool {
+	return c == '_' || unicode.IsLetter(c) || (!first && (unicode.IsDigit(c) || (definition && (c == '.' || c == '-'))))
+}
+
+func (p *dotenvParser) readSingleQuoted() (string, error) {
+	startLine := p.line
+	p.pos++
+	end := strings.IndexByte(p.data[p.pos:], '\'')
+	if end < 0 {
+		p.line = startLine
+		return "", p.errorf("unterminated single quoted value")
+	}
+	value := p.data[p.pos : p.pos+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *dotenvParser) readDoubleQuoted() (string, error) {
+	startLine := p.line
+	var value strings.Builder
+	p.pos++
+	for !p.eof() {
+		c := p.data[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return value.String(), nil
+		case '\\':
+			if p.pos+1 < len(p.data) {
+				p.pos++
+				switch escaped := p.data[p.pos]; escaped {
+				case 'n':
+					value.WriteByte('\n')
+				case 'r':
+					value.WriteByte('\r')
+				case 't':
+					value.WriteByte('\t')
+				case '"', '\\', '$':
+					value.WriteByte(escaped)
+				case '\n':
+					// Line continuation
+					p.line++
+				default:
+					value.WriteByte('\\')
+					value.WriteByte(escaped)
+				}
+				p.pos++
+				continue
+			}
+		case '$':
+			value.WriteString(p.readReference())
+			continue
+		case '\n':
+			p.line++
+		}
+		value.WriteByte(c)
+		p.pos++
+	}
+	p.line = startLine
+	return "", p.errorf("unterminated double quoted value")
+}
+
+// readUnquoted reads the value up to the end of the line or an inline comment (a # preceded by a
+// blank), and trims it
+func (p *dotenvParser) readUnquoted() string {
+	var value strings.Builder
+	for !p.eof() && p.data[p.pos] != '\n' {
+		c := p.data[p.pos]
+		if c == '#' && p.pos > 0 && (p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+			break
+		}
+		if c == '$' {
+			value.WriteString(p.readReference())
+			continue
+		}
+		value.WriteByte(c)
+		p.pos++
+	}
+	return strings.TrimRight(value.String(), " \t")
+}
+
+// readReference expands the $NAME or ${NAME} reference at the current position. A $ that is not
+// followed by a name is returned unchanged
+func (p *dotenvParser) readReference() string {
+	p.pos++
+	braced := !p.eof() && p.data[p.pos] == '{'
+	if braced {
+		end := strings.IndexByte(p.data[p.pos:], '}')
+		if end < 0 {
+			return "$"
+		}
+		name := p.data[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+		return p.resolve(name)
+	}
+	name := p.readName(false)
+	if len(name) == 0 {
+		return "$"
+	}
+	return p.resolve(name)
+}
+
+func (p *dotenvParser) resolve(name string) string {
+	if p.protected != nil {
+		if value, ok := p.protected(name); ok {
+			return value
+		}
+	}
+	if value, ok := p.defined[name]; ok {
+		return value
+	}
+	if p.lookup != nil {
+		if value, ok := p.lookup(name); ok {
+			return value
+		}
+	}
+	return ""
+}
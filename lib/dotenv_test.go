@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    []EnvVar
+		wantErr bool
+	}{
+		{
+			name:    "Simple values and comments",
+			content: "# A comment\n\nA=1\nexport B = two words  # inline comment\nC=a#b\n",
+			want:    []EnvVar{{"A", "1", false}, {"B", "two words", false}, {"C", "a#b", false}},
+		},
+		{
+			name:    "Quoted values",
+			content: `A='single $HOME "q"'` + "\n" + `B="double \"q\" \$HOME\tx"` + "\n" + `C=""`,
+			want:    []EnvVar{{"A", `single $HOME "q"`, true}, {"B", "double \"q\" $HOME\tx", false}, {"C", "", false}},
+		},
+		{
+			name:    "Multiline values",
+			content: "A=\"line 1\nline 2\"\nB='x\ny'\nC=\"escaped\\nnew line\"",
+			want:    []EnvVar{{"A", "line 1\nline 2", false}, {"B", "x\ny", true}, {"C", "escaped\nnew line", false}},
+		},
+		{
+			name:    "References",
+			content: "A=1\nB=${A}-$A\nC=\"$HOME/x\"\nD=${UNDEFINED}$\nE=$HOME.txt",
+			want:    []EnvVar{{"A", "1", false}, {"B", "1-1", false}, {"C", "/home/user/x", false}, {"D", "$", false}, {"E", "/home/user.txt", false}},
+		},
+		{
+			name:    "Windows line endings",
+			content: "A=1\r\nB=\"2\"\r\n",
+			want:    []EnvVar{{"A", "1", false}, {"B", "2", false}},
+		},
+		{
+			name:    "Unterminated quote",
+			content: "A=\"1\nB=2",
+			wantErr: true,
+		},
+		{
+			name:    "Missing equal",
+			content: "A 1",
+			wantErr: true,
+		},
+		{
+			name:    "Trailing garbage",
+			content: "A='1' 2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotenv(strings.NewReader(tt.content), "test.env", lookup, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDotenv() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDotenv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDotenvProtected(t *testing.T) {
+	protected := func(name string) (string, bool) {
+		if name == "HOST" {
+			return "prod", true
+		}
+		return "", false
+	}
+	got, err := ParseDotenv(strings.NewReader("HOST=localhost\nPORT=80\nURL=http://${HOST}:$PORT\n"), "test.env", nil, protected)
+	if err != nil {
+		t.Fatalf("ParseDotenv() error = %v", err)
+	}
+	want := []EnvVar{{"HOST", "localhost", false}, {"PORT", "80", false}, {"URL", "http://prod:80", false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDotenv() = %v, want %v", got, want)
+	}
+}
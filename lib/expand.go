@@ -0,0 +1,259 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// lateReferenceRexp matches a late expansion reference (%NAME%, optionally with a modifier as in
+// %NAME:-default%) at the start of a string
+var lateReferenceRexp = regexp.MustCompile(`^%([\w-]+)(?::([-?+])([^%]*))?%`)
+
+// bracedReferenceRexp matches the content of a ${NAME} reference, also with an optional modifier
+var bracedReferenceRexp = regexp.MustCompile(`^([\w-]+)(?::([-?+])(.*))?$`)
+
+// reference is a reference to a variable found on a value
+type reference struct {
+	name     string
+	modifier byte   // One of '-', '?', '+' or 0 if the reference has no modifier
+	word     string // The default value, error message or alternate value used by the modifier
+	length   int    // Length of the reference on the value
+}
+
+// ExpandOptions selects which variables are expanded by ExpandVariables. Note that the patterns
+// are used as they are, so they should be anchored to match whole variable names
+type ExpandOptions struct {
+	// If set, only the variables whose name matches it are expanded
+	Only *regexp.Regexp
+	// The variables whose name matches it are never expanded
+	Skip *regexp.Regexp
+	// The variables on Literal are never expanded either, as the single quoted values of dotenv files
+	Literal map[string]bool
+	// The variables on LateOnly only get their late (%NAME%) references expanded, as the ones from
+	// dotenv files, whose $ references are expanded by ParseDotenv
+	LateOnly map[string]bool
+}
+
+// shouldExpand returns true if the variable called name must be expanded
+func (o ExpandOptions) shouldExpand(name string) bool {
+	return (o.Only == nil || o.Only.MatchString(name)) && (o.Skip == nil || !o.Skip.MatchString(name)) && !o.Literal[name]
+}
+
+// ExpandErrors holds the error found expanding each variable that could not be expanded, by name
+type ExpandErrors map[string]error
+
+// Error lists all the errors, one per line, sorted by variable name
+func (e ExpandErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("cannot expand %s: %v", name, e[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ExpandVariables resolves the references on the values of vars, and returns the resulting values.
+// Both late (%NAME%) and shell like ($NAME and ${NAME}) references are supported, and they're
+// resolved against vars itself, transitively: if A=%B% and B=%C%, A gets the fully expanded value
+// of C. References to variables that do not exist expand to an empty string. A reference cycle
+// (such as A=%B% and B=%A%, or A=x%A%) is an error that lists the whole chain.
+//
+// References can also use the following shell like modifiers, where word is expanded too (and can
+// have references itself, as in ${NAME:-${OTHER}}):
+//
+//	%NAME:-word%  Expands to word if NAME does not exist or is empty
+//	%NAME:?word%  Fails, with word as the error message, if NAME does not exist or is empty
+//	%NAME:+word%  Expands to word if NAME exists and is not empty, and to an empty string otherwise
+//
+// A %% sequence expands to a literal %, so values can contain percent delimited text. The variables
+// excluded by opts keep their values untouched (so %% is not replaced on them either), and so do
+// the references to them.
+//
+// A variable that cannot be expanded (because of a cycle or a failed :? modifier, on its value or
+// on any variable it refers to) keeps its raw value, and its error is returned on the ExpandErrors.
+// The values of those variables should not be used: the variables that do not need to be expanded
+// should be excluded with opts instead.
+func ExpandVariables(vars map[string]string, opts ExpandOptions) (map[string]string, ExpandErrors) {
+	e := expander{
+		opts:      opts,
+		raw:       vars,
+		expanded:  make(map[string]string, len(vars)),
+		failed:    ExpandErrors{},
+		expanding: map[string]bool{},
+	}
+	// Sorting the names makes the errors deterministic. The values are anyway
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = e.resolve(name)
+	}
+	if len(e.failed) == 0 {
+		return e.expanded, nil
+	}
+	return e.expanded, e.failed
+}
+
+type expander struct {
+	opts      ExpandOptions
+	raw       map[string]string
+	expanded  map[string]string
+	failed    ExpandErrors
+	expanding map[string]bool
+	chain     []string
+}
+
+// resolve returns the expanded value of the variable called name. If it cannot be expanded, its
+// raw value is kept, and the error recorded on e.failed
+func (e *expander) resolve(name string) (string, error) {
+	if err, failed := e.failed[name]; failed {
+		return "", err
+	}
+	if value, done := e.expanded[name]; done {
+		return value, nil
+	}
+	rawValue, exists := e.raw[name]
+	if !exists {
+		return "", nil
+	}
+	if !e.opts.shouldExpand(name) {
+		e.expanded[name] = rawValue
+		return rawValue, nil
+	}
+	if e.expanding[name] {
+		return "", fmt.Errorf("reference cycle expanding variables: %s -> %s", strings.Join(e.chain, " -> "), name)
+	}
+
+	e.expanding[name] = true
+	e.chain = append(e.chain, name)
+	value, err := e.expand(rawValue, !e.opts.LateOnly[name])
+	e.chain = e.chain[:len(e.chain)-1]
+	delete(e.expanding, name)
+	if err != nil {
+		e.failed[name] = err
+		e.expanded[name] = rawValue
+		return "", err
+	}
+	e.expanded[name] = value
+	return value, nil
+}
+
+// expand replaces all the references on value with the (expanded) values they refer to. The shell
+// like ($NAME and ${NAME}) references are only replaced if shell is true
+func (e *expander) expand(value string, shell bool) (string, error) {
+	var rv strings.Builder
+	for pos := 0; pos < len(value); {
+		if strings.HasPrefix(value[pos:], "%%") {
+			rv.WriteByte('%')
+			pos += 2
+			continue
+		}
+		ref := parseReference(value[pos:])
+		if ref.length == 0 || (!shell && value[pos] == '$') {
+			rv.WriteByte(value[pos])
+			pos++
+			continue
+		}
+		resolved, err := e.apply(ref, shell)
+		if err != nil {
+			return "", err
+		}
+		rv.WriteString(resolved)
+		pos += ref.length
+	}
+	return rv.String(), nil
+}
+
+// apply returns the value ref expands to. shell is passed to expand for the modifier word
+func (e *expander) apply(ref reference, shell bool) (string, error) {
+	resolved, err := e.resolve(ref.name)
+	if err != nil {
+		return "", err
+	}
+	switch ref.modifier {
+	case '-':
+		if len(resolved) == 0 {
+			return e.expand(ref.word, shell)
+		}
+	case '?':
+		if len(resolved) == 0 {
+			message, err := e.expand(ref.word, shell)
+			if err != nil {
+				return "", err
+			}
+			if len(message) == 0 {
+				message = "variable is not set or empty"
+			}
+			return "", fmt.Errorf("%s: %s", ref.name, message)
+		}
+	case '+':
+		if len(resolved) == 0 {
+			return "", nil
+		}
+		return e.expand(ref.word, shell)
+	}
+	return resolved, nil
+}
+
+// parseReference returns the reference at the start of s. The length of the returned reference is
+// 0 if s does not start with a reference
+func parseReference(s string) (ref reference) {
+	switch s[0] {
+	case '%':
+		if match := lateReferenceRexp.FindStringSubmatch(s); match != nil {
+			ref = reference{name: match[1], word: match[3], length: len(match[0])}
+			if len(match[2]) > 0 {
+				ref.modifier = match[2][0]
+			}
+		}
+	case '$':
+		if strings.HasPrefix(s, "${") {
+			end := closingBrace(s)
+			if end < 0 {
+				return
+			}
+			if match := bracedReferenceRexp.FindStringSubmatch(s[2:end]); match != nil {
+				ref = reference{name: match[1], word: match[3], length: end + 1}
+				if len(match[2]) > 0 {
+					ref.modifier = match[2][0]
+				}
+			}
+			return
+		}
+		length := 1
+		for length < len(s) && isNameChar(rune(s[length]), length == 1, false) {
+			length++
+		}
+		if length > 1 {
+			ref = reference{name: s[1:length], length: length}
+		}
+	}
+	return
+}
+
+// closingBrace returns the position of the } that closes the ${ at the start of s, skipping the
+// ones that close any nested ${ reference, or -1 if there is none
+func closingBrace(s string) int {
+	depth := 0
+	for pos := 2; pos < len(s); pos++ {
+		switch {
+		case strings.HasPrefix(s[pos:], "${"):
+			depth++
+			pos++
+		case s[pos] == '}':
+			if depth == 0 {
+				return pos
+			}
+			depth--
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package lib
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExpandVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]string
+		opts     ExpandOptions
+		want     map[string]string
+		wantErrs map[string]string
+	}{
+		{
+			name: "No references",
+			vars: map[string]string{"A": "1", "B": "100% sure $"},
+			want: map[string]string{"A": "1", "B": "100% sure $"},
+		},
+		{
+			name: "Late references",
+			vars: map[string]string{"A": "Late %B%", "B": "value", "fight_ti-ng": "x%B%x"},
+			want: map[string]string{"A": "Late value", "B": "value", "fight_ti-ng": "xvaluex"},
+		},
+		{
+			name: "Shell references",
+			vars: map[string]string{"A": "$B/${B}", "B": "value", "C": "$B-$"},
+			want: map[string]string{"A": "value/value", "B": "value", "C": "value-$"},
+		},
+		{
+			name: "Transitive references",
+			vars: map[string]string{"A": "a%B%", "B": "b%C%", "C": "c${D}", "D": "d"},
+			want: map[string]string{"A": "abcd", "B": "bcd", "C": "cd", "D": "d"},
+		},
+		{
+			name: "Missing references",
+			vars: map[string]string{"A": "[%MISSING%][$MISSING]"},
+			want: map[string]string{"A": "[][]"},
+		},
+		{
+			name: "Default values",
+			vars: map[string]string{"A": "%MISSING:-def%/%EMPTY:-$B%/%B:-def%", "B": "b", "EMPTY": ""},
+			want: map[string]string{"A": "def/b/b", "B": "b", "EMPTY": ""},
+		},
+		{
+			name: "Alternate values",
+			vars: map[string]string{"A": "[%MISSING:+alt%][%B:+alt%][${B:+x}]", "B": "b"},
+			want: map[string]string{"A": "[][alt][x]", "B": "b"},
+		},
+		{
+			name: "Required values",
+			vars: map[string]string{"A": "%B:?B is required%", "B": "b"},
+			want: map[string]string{"A": "b", "B": "b"},
+		},
+		{
+			name:     "Missing required value",
+			vars:     map[string]string{"A": "%DB_HOST:?the database host must be set%", "B": "b"},
+			want:     map[string]string{"A": "%DB_HOST:?the database host must be set%", "B": "b"},
+			wantErrs: map[string]string{"A": "DB_HOST: the database host must be set"},
+		},
+		{
+			name:     "Missing required value without message",
+			vars:     map[string]string{"A": "${DB_HOST:?}", "B": "x%A%"},
+			want:     map[string]string{"A": "${DB_HOST:?}", "B": "x%A%"},
+			wantErrs: map[string]string{"A": "DB_HOST: variable is not set or empty", "B": "DB_HOST: variable is not set or empty"},
+		},
+		{
+			name: "Escaped percent signs",
+			vars: map[string]string{"A": "100%% %%B%% %%%B%%%", "B": "b", "C": `C:\%%WINDIR%%\x`},
+			want: map[string]string{"A": "100% %B% %b%", "B": "b", "C": `C:\%WINDIR%\x`},
+		},
+		{
+			name: "Variables that are never expanded",
+			vars: map[string]string{"LOG_PATTERN": "%d{ISO8601}% %B% %%", "DATE_FORMAT": "%Y%", "Y": "y", "A": "%DATE_FORMAT%"},
+			opts: ExpandOptions{Skip: regexp.MustCompile(`^(?:LOG_PATTERN|.*_FORMAT)$`)},
+			want: map[string]string{"LOG_PATTERN": "%d{ISO8601}% %B% %%", "DATE_FORMAT": "%Y%", "Y": "y", "A": "%Y%"},
+		},
+		{
+			name: "Only some variables are expanded",
+			vars: map[string]string{"APP_A": "%B%", "B": "b", "C": "%B%"},
+			opts: ExpandOptions{Only: regexp.MustCompile(`^APP_`)},
+			want: map[string]string{"APP_A": "b", "B": "b", "C": "%B%"},
+		},
+		{
+			name: "Literal and late only variables",
+			vars: map[string]string{"LIT": "%B% $B", "LATE": "%B% $B ${B} %%", "B": "b"},
+			opts: ExpandOptions{Literal: map[string]bool{"LIT": true}, LateOnly: map[string]bool{"LATE": true}},
+			want: map[string]string{"LIT": "%B% $B", "LATE": "b $B ${B} %", "B": "b"},
+		},
+		{
+			name:     "Self reference",
+			vars:     map[string]string{"A": "x%A%"},
+			want:     map[string]string{"A": "x%A%"},
+			wantErrs: map[string]string{"A": "A -> A"},
+		},
+		{
+			name:     "Reference cycle",
+			vars:     map[string]string{"A": "%B%", "B": "%C%", "C": "$A", "D": "%A%", "E": "%F%", "F": "f"},
+			want:     map[string]string{"A": "%B%", "B": "%C%", "C": "$A", "D": "%A%", "E": "f", "F": "f"},
+			wantErrs: map[string]string{"A": "A -> B -> C -> A", "B": "A -> B -> C -> A", "C": "A -> B -> C -> A", "D": "A -> B -> C -> A"},
+		},
+		{
+			name: "Nested references on modifiers",
+			vars: map[string]string{
+				"A": "${MISSING:-${B}}",
+				"C": "${MISSING:-${ALSO_MISSING:-c}}-${B:+[${B}]}",
+				"D": "${MISSING:-${B}",
+				"E": "${B:-}}",
+				"B": "b",
+			},
+			want: map[string]string{"A": "b", "C": "c-[b]", "D": "${MISSING:-b", "E": "b}", "B": "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := ExpandVariables(tt.vars, tt.opts)
+			if len(errs) != len(tt.wantErrs) {
+				t.Errorf("ExpandVariables() errors = %v, want %v", errs, tt.wantErrs)
+			}
+			for name, wantErr := range tt.wantErrs {
+				if err := errs[name]; err == nil || !strings.Contains(err.Error(), wantErr) {
+					t.Errorf("ExpandVariables() error for %s = %v, want %v", name, err, wantErr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandVariables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandErrors_Error(t *testing.T) {
+	_, errs := ExpandVariables(map[string]string{"B": "%B%", "A": "%MISSING:?must be set%"}, ExpandOptions{})
+	want := "cannot expand A: MISSING: must be set\ncannot expand B: reference cycle expanding variables: B -> B"
+	if errs == nil || errs.Error() != want {
+		t.Errorf("ExpandErrors.Error() = %q, want %q", errs, want)
+	}
+}
@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithPrefix returns the subset of t whose keys start with prefix. It never fails: the error is
+// only there so all the TemplateData methods have the same signature
+func (t TemplateData) WithPrefix(prefix string) (TemplateData, error) {
+	rv := make(TemplateData, len(t))
+	for k, v := range t {
+		if strings.HasPrefix(k, prefix) {
+			rv[k] = v
+		}
+	}
+	return rv, nil
+}
+
+// StripPrefix returns the subset of t whose keys start with prefix, with the prefix removed from the
+// keys. So with MYSVC_PORT=80, .StripPrefix "MYSVC_" returns PORT=80. A key that is just the prefix
+// is not included, since it would end up empty. As WithPrefix, it never fails
+func (t TemplateData) StripPrefix(prefix string) (TemplateData, error) {
+	rv := make(TemplateData, len(t))
+	for k, v := range t {
+		if stripped, found := strings.CutPrefix(k, prefix); found && len(stripped) > 0 {
+			rv[stripped] = v
+		}
+	}
+	return rv, nil
+}
+
+// LowerKeys returns a copy of t with all its keys lowercased. It returns an error if two keys end
+// up being the same
+func (t TemplateData) LowerKeys() (TemplateData, error) {
+	return t.mapKeys(strings.ToLower)
+}
+
+// UpperKeys returns a copy of t with all its keys uppercased. It returns an error if two keys end
+// up being the same
+func (t TemplateData) UpperKeys() (TemplateData, error) {
+	return t.mapKeys(strings.ToUpper)
+}
+
+// ReplaceInKeys returns a copy of t where every old on the keys is replaced with new, so for
+// example .ReplaceInKeys "_" "." turns DB_HOST into DB.HOST. It returns an error if two keys end up
+// being the same
+func (t TemplateData) ReplaceInKeys(old, new string) (TemplateData, error) {
+	return t.mapKeys(func(k string) string {
+		return strings.ReplaceAll(k, old, new)
+	})
+}
+
+// mapKeys returns a copy of t where each key is replaced with the result of calling mapper on it
+func (t TemplateData) mapKeys(mapper func(string) string) (TemplateData, error) {
+	rv := make(TemplateData, len(t))
+	origins := make(map[string]string, len(t))
+	for k, v := range t {
+		newKey := mapper(k)
+		if origin, exists := origins[newKey]; exists {
+			if origin > k {
+				origin, k = k, origin
+			}
+			return nil, fmt.Errorf("both %s and %s are converted to %s", origin, k, newKey)
+		}
+		origins[newKey] = k
+		rv[newKey] = v
+	}
+	return rv, nil
+}
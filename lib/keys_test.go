@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+var keysTestData = TemplateData{
+	"MYSVC_DB_HOST": "localhost",
+	"MYSVC_DB_PORT": "5432",
+	"MYSVC_":        "empty",
+	"OTHER_DB_HOST": "other",
+}
+
+func TestTemplateData_WithPrefix(t *testing.T) {
+	want := TemplateData{"MYSVC_DB_HOST": "localhost", "MYSVC_DB_PORT": "5432", "MYSVC_": "empty"}
+	if got, err := keysTestData.WithPrefix("MYSVC_"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("WithPrefix() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestTemplateData_StripPrefix(t *testing.T) {
+	want := TemplateData{"DB_HOST": "localhost", "DB_PORT": "5432"}
+	if got, err := keysTestData.StripPrefix("MYSVC_"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("StripPrefix() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestTemplateData_KeyTransforms(t *testing.T) {
+	data := TemplateData{"DB_HOST": "localhost", "DB_PORT": "5432"}
+	lower, err := data.LowerKeys()
+	if want := (TemplateData{"db_host": "localhost", "db_port": "5432"}); err != nil || !reflect.DeepEqual(lower, want) {
+		t.Errorf("LowerKeys() = %v, %v, want %v", lower, err, want)
+	}
+	upper, err := lower.UpperKeys()
+	if err != nil || !reflect.DeepEqual(upper, data) {
+		t.Errorf("UpperKeys() = %v, %v, want %v", upper, err, data)
+	}
+	dotted, err := lower.ReplaceInKeys("_", ".")
+	if want := (TemplateData{"db.host": "localhost", "db.port": "5432"}); err != nil || !reflect.DeepEqual(dotted, want) {
+		t.Errorf("ReplaceInKeys() = %v, %v, want %v", dotted, err, want)
+	}
+
+	if _, err := (TemplateData{"A": "1", "a": "2"}).LowerKeys(); err == nil || err.Error() != "both A and a are converted to a" {
+		t.Errorf("LowerKeys() expected a collision error, got %v", err)
+	}
+}
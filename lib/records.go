@@ -0,0 +1,139 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// recordSeparator separates both the field names and the field values of a record
+const recordSeparator = ";"
+
+// Records groups the indexed variables whose names start with prefix into records, with the
+// (recordSeparator separated) field names passed on fields. So for example
+//
+//	.Records "VAULT_SECRET_" "path;key;destination"
+//
+// with VAULT_SECRET_1="secret/a;password;/secrets/a" and VAULT_SECRET_2="secret/b;user;/secrets/b"
+// returns two records, {path: secret/a, key: password, destination: /secrets/a} and
+// {path: secret/b, ...}. Records are sorted by their numeric index (so VAULT_SECRET_2 goes before
+// VAULT_SECRET_10). Fields can also be set (or overridden) one by one, with variables like
+// VAULT_SECRET_1_PATH (the field name is not case-sensitive). It returns an error if a value does
+// not have as many fields as expected, if a variable refers to an unknown field, if a record ends
+// up with any field unset, or if two variables clash: when they write the same index differently
+// (as VAULT_SECRET_1 and VAULT_SECRET_01), or set the same field (as VAULT_SECRET_1_PATH and
+// VAULT_SECRET_1_path).
+func (t TemplateData) Records(prefix string, fields string) ([]TemplateData, error) {
+	fieldNames := strings.Split(fields, recordSeparator)
+	fieldsByName := make(map[string]string, len(fieldNames))
+	for _, field := range fieldNames {
+		fieldsByName[strings.ToUpper(field)] = field
+	}
+	exp, err := compilePattern("^" + regexp.QuoteMeta(prefix) + `(\d+)(?:_(.+))?$`)
+	if err != nil {
+		return nil, err
+	}
+
+	records := recordSet{records: map[int]TemplateData{}, firstKeys: map[int]recordKey{}}
+	// Per field variables are applied after the whole record ones, so they always win
+	var perFieldKeys []string
+	for key, value := range t {
+		match := exp.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		if len(match[2]) > 0 {
+			perFieldKeys = append(perFieldKeys, key)
+			continue
+		}
+		values := value.Split(recordSeparator)
+		if len(values) != len(fieldNames) {
+			return nil, fmt.Errorf("invalid record %s: expected %d fields (%s), got %d", key, len(fieldNames), fields, len(values))
+		}
+		record, err := records.get(key, match[1])
+		if err != nil {
+			return nil, err
+		}
+		for i, field := range fieldNames {
+			record[field] = values[i]
+		}
+	}
+	fieldKeys := map[string]string{}
+	for _, key := range perFieldKeys {
+		match := exp.FindStringSubmatch(key)
+		field, ok := fieldsByName[strings.ToUpper(match[2])]
+		if !ok {
+			return nil, fmt.Errorf("invalid record variable %s: unknown field %s. Valid fields are %s", key, match[2], fields)
+		}
+		record, err := records.get(key, match[1])
+		if err != nil {
+			return nil, err
+		}
+		fieldKey := match[1] + recordSeparator + field
+		if other, set := fieldKeys[fieldKey]; set {
+			return nil, clashError(key, other, "both set the field "+field)
+		}
+		fieldKeys[fieldKey] = key
+		record[field] = t[key]
+	}
+
+	indexes := make([]int, 0, len(records.records))
+	for index := range records.records {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	rv := make([]TemplateData, 0, len(records.records))
+	for _, index := range indexes {
+		record := records.records[index]
+		for _, field := range fieldNames {
+			if _, ok := record[field]; !ok {
+				return nil, fmt.Errorf("invalid record %s%d: field %s is not set", prefix, index, field)
+			}
+		}
+		rv = append(rv, record)
+	}
+	return rv, nil
+}
+
+// recordSet holds the records built by Records, by index
+type recordSet struct {
+	records map[int]TemplateData
+	// firstKeys holds the first variable found for each index, to report the variables that write
+	// the same index differently
+	firstKeys map[int]recordKey
+}
+
+// recordKey is a variable of a record, and its index as written on it
+type recordKey struct {
+	key   string
+	index string
+}
+
+// get returns the record with the passed index (as written on the variable key), creating it if
+// needed. It fails if the index was written differently by another variable
+func (r recordSet) get(key, index string) (TemplateData, error) {
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return nil, fmt.Errorf("invalid record variable %s: invalid index %s: %v", key, index, err)
+	}
+	if first, found := r.firstKeys[i]; found {
+		if first.index != index {
+			return nil, clashError(key, first.key, "both have the index "+strconv.Itoa(i))
+		}
+		return r.records[i], nil
+	}
+	r.firstKeys[i] = recordKey{key: key, index: index}
+	r.records[i] = TemplateData{}
+	return r.records[i], nil
+}
+
+// clashError reports that the variables a and b clash, sorting them so the error is the same
+// whatever the map iteration order
+func clashError(a, b, reason string) error {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Errorf("invalid record variables %s and %s: %s", a, b, reason)
+}
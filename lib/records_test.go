@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateData_Records(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       TemplateData
+		prefix  string
+		fields  string
+		want    []TemplateData
+		wantErr string
+	}{
+		{
+			name: "Whole records sorted by index",
+			t: TemplateData{
+				"VAULT_SECRET_10": "secret/c;k3;/c",
+				"VAULT_SECRET_2":  "secret/b;k2;/b",
+				"VAULT_SECRET_1":  "secret/a;k1;/a",
+				"VAULT_SECRETS":   "not a record",
+				"OTHER_1":         "not a record either",
+			},
+			prefix: "VAULT_SECRET_",
+			fields: "path;key;destination",
+			want: []TemplateData{
+				{"path": "secret/a", "key": "k1", "destination": "/a"},
+				{"path": "secret/b", "key": "k2", "destination": "/b"},
+				{"path": "secret/c", "key": "k3", "destination": "/c"},
+			},
+		},
+		{
+			name: "Per field variables",
+			t: TemplateData{
+				"VAULT_SECRET_1":             "secret/a;k1;/a",
+				"VAULT_SECRET_1_DESTINATION": "/override",
+				"VAULT_SECRET_2_PATH":        "secret/b",
+				"VAULT_SECRET_2_KEY":         "k2",
+				"VAULT_SECRET_2_destination": "/b",
+			},
+			prefix: "VAULT_SECRET_",
+			fields: "path;key;destination",
+			want: []TemplateData{
+				{"path": "secret/a", "key": "k1", "destination": "/override"},
+				{"path": "secret/b", "key": "k2", "destination": "/b"},
+			},
+		},
+		{
+			name:   "No records",
+			t:      TemplateData{"A": "1"},
+			prefix: "VAULT_SECRET_",
+			fields: "path;key",
+			want:   []TemplateData{},
+		},
+		{
+			name:    "Wrong number of fields",
+			t:       TemplateData{"CONSUL_KV_1": "config/whatever"},
+			prefix:  "CONSUL_KV_",
+			fields:  "path;destination",
+			wantErr: "invalid record CONSUL_KV_1: expected 2 fields (path;destination), got 1",
+		},
+		{
+			name:    "Unknown field",
+			t:       TemplateData{"CONSUL_KV_1_DEST": "config/whatever"},
+			prefix:  "CONSUL_KV_",
+			fields:  "path;destination",
+			wantErr: "unknown field DEST",
+		},
+		{
+			name:    "Missing field",
+			t:       TemplateData{"CONSUL_KV_1_PATH": "config/whatever"},
+			prefix:  "CONSUL_KV_",
+			fields:  "path;destination",
+			wantErr: "invalid record CONSUL_KV_1: field destination is not set",
+		},
+		{
+			name:    "Same index written differently",
+			t:       TemplateData{"VAULT_SECRET_1": "secret/a;k1", "VAULT_SECRET_01": "secret/b;k2"},
+			prefix:  "VAULT_SECRET_",
+			fields:  "path;key",
+			wantErr: "invalid record variables VAULT_SECRET_01 and VAULT_SECRET_1: both have the index 1",
+		},
+		{
+			name:    "Same index written differently on a per field variable",
+			t:       TemplateData{"VAULT_SECRET_1": "secret/a;k1", "VAULT_SECRET_001_KEY": "k2"},
+			prefix:  "VAULT_SECRET_",
+			fields:  "path;key",
+			wantErr: "invalid record variables VAULT_SECRET_001_KEY and VAULT_SECRET_1: both have the index 1",
+		},
+		{
+			name:    "Same field set twice",
+			t:       TemplateData{"VAULT_SECRET_1_PATH": "secret/a", "VAULT_SECRET_1_path": "secret/b", "VAULT_SECRET_1_KEY": "k1"},
+			prefix:  "VAULT_SECRET_",
+			fields:  "path;key",
+			wantErr: "invalid record variables VAULT_SECRET_1_PATH and VAULT_SECRET_1_path: both set the field path",
+		},
+		{
+			name:    "Index too big",
+			t:       TemplateData{"VAULT_SECRET_99999999999999999999": "secret/a;k1"},
+			prefix:  "VAULT_SECRET_",
+			fields:  "path;key",
+			wantErr: "invalid record variable VAULT_SECRET_99999999999999999999: invalid index",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.t.Records(tt.prefix, tt.fields)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Records() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Records() unexpected error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Records() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
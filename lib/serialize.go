@@ -0,0 +1,23 @@
+package lib
+
+import "envtemplate/template"
+
+// ToYAML returns t as a YAML mapping, sorted by key
+func (t TemplateData) ToYAML() (template.ExtendedString, error) {
+	return template.EncodeYAML(map[string]template.ExtendedString(t))
+}
+
+// ToTOML returns t as a TOML document, sorted by key
+func (t TemplateData) ToTOML() (template.ExtendedString, error) {
+	return template.EncodeTOML(map[string]template.ExtendedString(t))
+}
+
+// ToHCL returns t as a list of HCL attributes (one NAME = "value" line per variable), sorted by key
+func (t TemplateData) ToHCL() (template.ExtendedString, error) {
+	return template.EncodeHCLAttributes(map[string]template.ExtendedString(t))
+}
+
+// ToEnv returns t as dotenv lines (one NAME="value" line per variable), sorted by key
+func (t TemplateData) ToEnv() (template.ExtendedString, error) {
+	return template.EncodeEnv(map[string]template.ExtendedString(t))
+}
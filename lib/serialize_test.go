@@ -0,0 +1,19 @@
+package lib
+
+import "testing"
+
+func TestTemplateData_Serialization(t *testing.T) {
+	data := TemplateData{"DB_PASS": `p"$1`, "DB_HOST": "localhost"}
+	if got, err := data.ToYAML(); err != nil || got != "DB_HOST: localhost\nDB_PASS: p\"$1" {
+		t.Errorf("ToYAML() = %q, %v", got, err)
+	}
+	if got, err := data.ToTOML(); err != nil || got != "DB_HOST = \"localhost\"\nDB_PASS = \"p\\\"$1\"\n" {
+		t.Errorf("ToTOML() = %q, %v", got, err)
+	}
+	if got, err := data.ToHCL(); err != nil || got != "DB_HOST = \"localhost\"\nDB_PASS = \"p\\\"$1\"\n" {
+		t.Errorf("ToHCL() = %q, %v", got, err)
+	}
+	if got, err := data.ToEnv(); err != nil || got != "DB_HOST=\"localhost\"\nDB_PASS=\"p\\\"\\$1\"\n" {
+		t.Errorf("ToEnv() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,149 @@
+package lib
+
+import (
+	"envtemplate/template"
+	"sort"
+	"strings"
+)
+
+// Entry is a single variable of a TemplateData, for the cases where the order matters
+type Entry struct {
+	Key   string
+	Value template.ExtendedString
+}
+
+// declarationOrder holds the position where each variable was declared, as set by
+// SetDeclarationOrder
+var declarationOrder = map[string]int{}
+
+// SetDeclarationOrder sets the order the variables were declared in, which is the order used by
+// FilterDeclared. Variables that appear more than once keep their first position
+func SetDeclarationOrder(names []string) {
+	declarationOrder = make(map[string]int, len(names))
+	for i, name := range names {
+		if _, ok := declarationOrder[name]; !ok {
+			declarationOrder[name] = i
+		}
+	}
+}
+
+// FilterSorted works like Filter, but it returns the matching variables as a list sorted in natural
+// order: the numbers inside the names are compared by their numeric value, so FILE_2 goes before
+// FILE_10.
+func (t TemplateData) FilterSorted(pattern string) ([]Entry, error) {
+	return t.filterEntries(pattern, naturalLess)
+}
+
+// FilterBySuffix works like Filter, but it returns the matching variables as a list sorted by the
+// numeric suffix of their names, so B_2 goes before A_10. Variables without a numeric suffix go first,
+// in natural order, as do the ones that share the same suffix.
+func (t TemplateData) FilterBySuffix(pattern string) ([]Entry, error) {
+	return t.filterEntries(pattern, func(a, b string) bool {
+		aPrefix, aSuffix := splitNumericSuffix(a)
+		bPrefix, bSuffix := splitNumericSuffix(b)
+		if c := compareNumbers(aSuffix, bSuffix); c != 0 {
+			return c < 0
+		}
+		if aPrefix != bPrefix {
+			return naturalLess(aPrefix, bPrefix)
+		}
+		return naturalLess(a, b)
+	})
+}
+
+// FilterDeclared works like Filter, but it returns the matching variables as a list sorted in the
+// order they were declared: first the ones from the environment, and then the ones from the env
+// files, in the order they appear there. Variables whose declaration order is not known go last, in
+// natural order.
+func (t TemplateData) FilterDeclared(pattern string) ([]Entry, error) {
+	return t.filterEntries(pattern, func(a, b string) bool {
+		aPos, aKnown := declarationOrder[a]
+		bPos, bKnown := declarationOrder[b]
+		switch {
+		case aKnown && bKnown:
+			return aPos < bPos
+		case aKnown != bKnown:
+			return aKnown
+		default:
+			return naturalLess(a, b)
+		}
+	})
+}
+
+// filterEntries returns the variables whose name matches pattern, sorted using less
+func (t TemplateData) filterEntries(pattern string, less func(a, b string) bool) ([]Entry, error) {
+	exp, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	rv := make([]Entry, 0, len(t))
+	for k, v := range t {
+		if exp.MatchString(k) {
+			rv = append(rv, Entry{Key: k, Value: v})
+		}
+	}
+	sort.Slice(rv, func(i, j int) bool {
+		return less(rv[i].Key, rv[j].Key)
+	})
+	return rv, nil
+}
+
+// naturalLess compares a and b chunk by chunk, where the chunks made of digits are compared by their
+// numeric value, and the rest lexically. Names that are only different on their leading zeros (as
+// A_1 and A_01) are compared lexically, so the order is always the same
+func naturalLess(a, b string) bool {
+	fullA, fullB := a, b
+	for len(a) > 0 && len(b) > 0 {
+		aChunk, aRest := nextChunk(a)
+		bChunk, bRest := nextChunk(b)
+		if isDigit(aChunk[0]) && isDigit(bChunk[0]) {
+			if c := compareNumbers(aChunk, bChunk); c != 0 {
+				return c < 0
+			}
+		}
+		if aChunk != bChunk {
+			return aChunk < bChunk
+		}
+		a, b = aRest, bRest
+	}
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return fullA < fullB
+}
+
+// nextChunk splits s after its first run of digits or non digits
+func nextChunk(s string) (chunk, rest string) {
+	digits := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// splitNumericSuffix splits s into its prefix and its trailing digits
+func splitNumericSuffix(s string) (prefix, suffix string) {
+	i := len(s)
+	for i > 0 && isDigit(s[i-1]) {
+		i--
+	}
+	return s[:i], s[i:]
+}
+
+// compareNumbers compares two strings of digits (of any length) by their numeric value. An empty
+// string is smaller than any number
+func compareNumbers(a, b string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) - len(b)
+	}
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
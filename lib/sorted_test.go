@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+var sortTestData = TemplateData{
+	"FILE_10": "ten",
+	"FILE_2":  "two",
+	"FILE_1":  "one",
+	"FILE":    "none",
+	"A_3":     "a3",
+	"OTHER":   "other",
+}
+
+func entryKeys(entries []Entry) []string {
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.Key
+	}
+	return keys
+}
+
+func TestTemplateData_FilterSorted(t *testing.T) {
+	got, err := sortTestData.FilterSorted("^(FILE|A)")
+	if err != nil {
+		t.Fatalf("FilterSorted() unexpected error = %v", err)
+	}
+	if want := []string{"A_3", "FILE", "FILE_1", "FILE_2", "FILE_10"}; !reflect.DeepEqual(entryKeys(got), want) {
+		t.Errorf("FilterSorted() = %v, want %v", entryKeys(got), want)
+	}
+	if got[4].Value != "ten" {
+		t.Errorf("FilterSorted() value = %v, want ten", got[4].Value)
+	}
+	if _, err := sortTestData.FilterSorted("[a-"); err == nil {
+		t.Errorf("FilterSorted() expected an error for an invalid pattern")
+	}
+}
+
+func TestTemplateData_FilterBySuffix(t *testing.T) {
+	got, err := sortTestData.FilterBySuffix("^(FILE|A)")
+	if err != nil {
+		t.Fatalf("FilterBySuffix() unexpected error = %v", err)
+	}
+	if want := []string{"FILE", "FILE_1", "FILE_2", "A_3", "FILE_10"}; !reflect.DeepEqual(entryKeys(got), want) {
+		t.Errorf("FilterBySuffix() = %v, want %v", entryKeys(got), want)
+	}
+
+	// The variables that share a suffix are always in the same order, whatever the map iteration
+	tied := TemplateData{"B_2": "", "A_2": "", "A_02": "", "C_1": "", "A_1": "", "A_01": ""}
+	for i := 0; i < 20; i++ {
+		got, err := tied.FilterBySuffix("")
+		if err != nil {
+			t.Fatalf("FilterBySuffix() unexpected error = %v", err)
+		}
+		if want := []string{"A_01", "A_1", "C_1", "A_02", "A_2", "B_2"}; !reflect.DeepEqual(entryKeys(got), want) {
+			t.Fatalf("FilterBySuffix() = %v, want %v", entryKeys(got), want)
+		}
+	}
+}
+
+func TestTemplateData_FilterDeclared(t *testing.T) {
+	SetDeclarationOrder([]string{"FILE_2", "OTHER", "FILE_10", "FILE_2", "FILE_1"})
+	defer SetDeclarationOrder(nil)
+
+	got, err := sortTestData.FilterDeclared("^FILE")
+	if err != nil {
+		t.Fatalf("FilterDeclared() unexpected error = %v", err)
+	}
+	if want := []string{"FILE_2", "FILE_10", "FILE_1", "FILE"}; !reflect.DeepEqual(entryKeys(got), want) {
+		t.Errorf("FilterDeclared() = %v, want %v", entryKeys(got), want)
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"FILE_2", "FILE_10", true},
+		{"FILE_10", "FILE_2", false},
+		{"FILE_02", "FILE_10", true},
+		{"a1b2", "a1b10", true},
+		{"abc", "abd", true},
+		{"ab", "abc", true},
+		{"abc", "abc", false},
+		{"A_01", "A_1", true},
+		{"A_1", "A_01", false},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,22 +1,38 @@
 package lib
 
 import (
-	"fmt"
 	"envtemplate/template"
-	"os"
+	"fmt"
 	"regexp"
+	"sync"
 )
 
 // TemplateData is the data that will be passed to the template evaluator.
 type TemplateData map[string]template.ExtendedString
 
-// Filter returns a subset of T where the keys match the passed pattern. It will return an empty
-// map and log an error if the pattern is not a valid one
-func (t TemplateData) Filter(pattern string) TemplateData {
+// compiledPatterns caches the patterns compiled by compilePattern, since the same pattern is
+// usually evaluated many times (for example, inside a range)
+var compiledPatterns sync.Map
+
+// compilePattern returns the compiled version of pattern, compiling it only the first time
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if exp, ok := compiledPatterns.Load(pattern); ok {
+		return exp.(*regexp.Regexp), nil
+	}
 	exp, err := regexp.Compile(pattern)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Invalid pattern: %s - error: %v", pattern, err)
-		return TemplateData{}
+		return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+	}
+	compiledPatterns.Store(pattern, exp)
+	return exp, nil
+}
+
+// Filter returns a subset of T where the keys match the passed pattern. It returns an error if the
+// pattern is not a valid one, which aborts the template execution
+func (t TemplateData) Filter(pattern string) (TemplateData, error) {
+	exp, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
 	}
 	rv := make(TemplateData, len(t))
 	for k, v := range t {
@@ -24,5 +40,5 @@ func (t TemplateData) Filter(pattern string) TemplateData {
 			rv[k] = v
 		}
 	}
-	return rv
+	return rv, nil
 }
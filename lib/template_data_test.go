@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplateData_Filter(t *testing.T) {
+	data := TemplateData{"FILE": "f", "FILE_1": "f1", "FILE_2": "f2", "OTHER": "o"}
+	tests := []struct {
+		name    string
+		pattern string
+		want    TemplateData
+		wantErr bool
+	}{
+		{
+			name:    "Matching pattern",
+			pattern: `^FILE_\d+$`,
+			want:    TemplateData{"FILE_1": "f1", "FILE_2": "f2"},
+		},
+		{
+			name:    "Same pattern again",
+			pattern: `^FILE_\d+$`,
+			want:    TemplateData{"FILE_1": "f1", "FILE_2": "f2"},
+		},
+		{
+			name:    "No matches",
+			pattern: `^NOPE`,
+			want:    TemplateData{},
+		},
+		{
+			name:    "Invalid pattern",
+			pattern: `^FILE_(\d+$`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := data.Filter(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Filter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
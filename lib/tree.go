@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"encoding/json"
+	"envtemplate/template"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTreeSeparator is the separator used by TemplateData.Tree when none is passed
+const DefaultTreeSeparator = "__"
+
+// Tree is a hierarchical view of a TemplateData. Each value is either a template.ExtendedString (a
+// leaf) or another Tree (a branch).
+type Tree map[string]any
+
+// Tree returns a hierarchical view of t, splitting the variable names by separator (which is
+// DefaultTreeSeparator if not passed). So with APP__DB__HOST=localhost and APP__DB__PORT=5432,
+// .Tree.APP.DB is a branch with HOST and PORT as leaves. Names with empty parts (like __A or
+// A____B) are not split, and are added to the root as they are. It returns an error if a variable
+// is both a leaf and a branch (as in APP__DB=x and APP__DB__HOST=y).
+func (t TemplateData) Tree(separator ...string) (Tree, error) {
+	sep := DefaultTreeSeparator
+	if len(separator) > 0 {
+		if len(separator) > 1 || len(separator[0]) == 0 {
+			return nil, fmt.Errorf("tree expects a single non empty separator, got %q", separator)
+		}
+		sep = separator[0]
+	}
+
+	rv := Tree{}
+	for key, value := range t {
+		path := strings.Split(key, sep)
+		for _, part := range path {
+			if len(part) == 0 {
+				path = []string{key}
+				break
+			}
+		}
+		if err := rv.add(path, value, sep); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// add sets value as the leaf at path, creating the needed branches
+func (tr Tree) add(path []string, value template.ExtendedString, sep string) error {
+	node := tr
+	for i, part := range path[:len(path)-1] {
+		switch child := node[part].(type) {
+		case nil:
+			branch := Tree{}
+			node[part] = branch
+			node = branch
+		case Tree:
+			node = child
+		default:
+			return fmt.Errorf("%s is both a value and a branch of the tree", strings.Join(path[:i+1], sep))
+		}
+	}
+	leaf := path[len(path)-1]
+	if _, exists := node[leaf]; exists {
+		return fmt.Errorf("%s is both a value and a branch of the tree", strings.Join(path, sep))
+	}
+	node[leaf] = value
+	return nil
+}
+
+// ToJSON returns tr as a JSON object
+func (tr Tree) ToJSON() (template.ExtendedString, error) {
+	data, err := json.Marshal(tr)
+	return template.ExtendedString(data), err
+}
+
+// ToYAML returns tr as a YAML document
+func (tr Tree) ToYAML() (template.ExtendedString, error) {
+	data, err := yaml.Marshal(tr)
+	return template.ExtendedString(data), err
+}
@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"envtemplate/template"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type es = template.ExtendedString
+
+func TestTemplateData_Tree(t *testing.T) {
+	tests := []struct {
+		name      string
+		t         TemplateData
+		separator []string
+		want      Tree
+		wantErr   string
+	}{
+		{
+			name: "Default separator",
+			t: TemplateData{
+				"APP__DB__HOST": "localhost",
+				"APP__DB__PORT": "5432",
+				"APP__NAME":     "app",
+				"OTHER":         "other",
+				"__HIDDEN":      "hidden",
+			},
+			want: Tree{
+				"APP": Tree{
+					"DB":   Tree{"HOST": es("localhost"), "PORT": es("5432")},
+					"NAME": es("app"),
+				},
+				"OTHER":    es("other"),
+				"__HIDDEN": es("hidden"),
+			},
+		},
+		{
+			name:      "Custom separator",
+			t:         TemplateData{"APP_DB_HOST": "localhost", "APP_NAME": "app"},
+			separator: []string{"_"},
+			want:      Tree{"APP": Tree{"DB": Tree{"HOST": es("localhost")}, "NAME": es("app")}},
+		},
+		{
+			name:    "Value and branch",
+			t:       TemplateData{"APP__DB": "x", "APP__DB__HOST": "localhost"},
+			wantErr: "APP__DB is both a value and a branch",
+		},
+		{
+			name:      "Empty separator",
+			t:         TemplateData{"A": "x"},
+			separator: []string{""},
+			wantErr:   "single non empty separator",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.t.Tree(tt.separator...)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Tree() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Tree() unexpected error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTree_Serialization(t *testing.T) {
+	tree, _ := TemplateData{"APP__DB__HOST": "localhost", "APP__NAME": "app"}.Tree()
+	if got, err := tree.ToJSON(); err != nil || got != `{"APP":{"DB":{"HOST":"localhost"},"NAME":"app"}}` {
+		t.Errorf("ToJSON() = %v, %v", got, err)
+	}
+	if got, err := tree.ToYAML(); err != nil || got != "APP:\n    DB:\n        HOST: localhost\n    NAME: app\n" {
+		t.Errorf("ToYAML() = %q, %v", got, err)
+	}
+}
@@ -7,18 +7,32 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/sprig/v3"
 )
 
 type commandlineFlags struct {
-	OutputFile string `flag:"o,out;File to write the result to"`
-	InputFile  string `flag:"i,in;File to read the template from"`
+	OutputFile      string           `flag:"o,out;File to write the result to"`
+	InputFile       string           `flag:"i,in;File to read the template from"`
+	InputDir        string           `flag:"input-dir;Directory to read the templates from. Its whole tree is rendered into output-dir"`
+	OutputDir       string           `flag:"output-dir;Directory where the rendered input-dir tree will be written"`
+	TemplateSuffix  string           `flag:"suffix;When rendering a directory, only the files ending with this suffix (e.g. .tmpl) are templates, and the suffix is removed from the output name. Any other file is copied unchanged"`
+	LeftDelim       string           `flag:"left-delim;Left delimiter of the template actions. Templates can override it on their header"`
+	RightDelim      string           `flag:"right-delim;Right delimiter of the template actions. Templates can override it on their header"`
+	Strict          bool             `flag:"strict;Fail if the template references a variable (or map key) that does not exist, instead of rendering an empty value"`
+	EnvFiles        utils.StringList `flag:"env-file;Dotenv file to load variables from. Can be repeated, and later files override earlier ones"`
+	EnvFileOverride bool             `flag:"env-file-override;Let the variables from env-file override the ones from the environment. By default the environment takes precedence"`
+	NoExpand        utils.StringList `flag:"no-expand;Regular expression of variable names whose values are never expanded. Can be repeated"`
+	ExpandOnly      utils.StringList `flag:"expand-only;Regular expression of variable names whose values are expanded. If set, no other variable is. Can be repeated"`
+	CertMinValidity time.Duration    `flag:"cert-min-validity;If set, loading a certificate that expires in less than this (e.g. 720h) fails the evaluation"`
+	AutoEscape      bool             `flag:"auto-escape;Escape the output of every action according to the extension of the output file: .sh values are shell quoted, .env ones dotenv quoted and .xml ones XML escaped. Templates can set (or disable) the escaping on their header"`
+	FileRoots       utils.StringList `flag:"file-root;Directory the templates can load files from. If set, loading any file outside the file roots fails the evaluation. Can be repeated"`
+	DataFiles       utils.StringList `flag:"data;Structured data file to mount on the template context, as name=path. The file can be JSON, YAML or TOML (based on its extension) and it is reachable as .Data.name. Can be repeated"`
 }
 
 func checkOptions(cf commandlineFlags) (writer io.Writer, tmplt *template.Template, err error) {
@@ -26,6 +40,11 @@ func checkOptions(cf commandlineFlags) (writer io.Writer, tmplt *template.Templa
 	writer = os.Stdout
 	err = nil
 
+	if len(cf.InputDir) > 0 || len(cf.OutputDir) > 0 {
+		err = checkDirectoryOptions(cf)
+		return
+	}
+
 	if len(cf.OutputFile) > 0 {
 		if writer, err = os.Create(cf.OutputFile); err != nil {
 			err = fmt.Errorf("cannot open output file %s. Error: %+v\n", cf.OutputFile, err)
@@ -35,45 +54,189 @@ func checkOptions(cf commandlineFlags) (writer io.Writer, tmplt *template.Templa
 
 	if len(cf.InputFile) > 0 {
 		if reader, err = os.Open(cf.InputFile); err != nil {
-			err = fmt.Errorf("cannot open input file %s. Error: %+v\n", cf.OutputFile, err)
+			err = fmt.Errorf("cannot open input file %s. Error: %+v\n", cf.InputFile, err)
 			return
 		}
 	}
 	var tmplData []byte
 
-	if tmplData, err = ioutil.ReadAll(reader); err != nil {
+	if tmplData, err = io.ReadAll(reader); err != nil {
 		err = fmt.Errorf("error parsing input template (%s): %v", cf.InputFile, err)
 		return
 	}
 
+	name := cf.InputFile
+	if len(name) == 0 {
+		name = "stdin"
+	}
+	tmplt, err = newTemplate(name, cf.OutputFile, tmplData, cf)
+	return
+}
+
+// newTemplate parses tmplData as a template called name, using the delimiters, options and functions
+// that all our templates share. The name should be the file the template was read from, since it's
+// what execution errors will report. The delimiters are the ones from the command line, unless the
+// template declares its own on a header line. outputName is the file the template will be rendered
+// to (empty for stdout), which selects the escaping when cf.AutoEscape is set.
+func newTemplate(name, outputName string, tmplData []byte, cf commandlineFlags) (tmplt *template.Template, err error) {
+	defaults := templateHeader{LeftDelim: cf.LeftDelim, RightDelim: cf.RightDelim}
+	if cf.AutoEscape {
+		defaults.Escape = escapeModeForPath(outputName)
+	}
+	header, tmplData, err := parseHeader(tmplData, defaults)
+	if err != nil {
+		err = fmt.Errorf("error parsing template header: %v\n", err)
+		return
+	}
+	missingKey := "missingkey=zero"
+	funcs := sprig.FuncMap()
+	for name, fn := range templateUtils.FuncMap() {
+		funcs[name] = fn
+	}
+	if cf.Strict {
+		missingKey = "missingkey=error"
+		funcs["index"] = strictIndex
+	}
 	tmplt = template.
-		New("root").
-		Delims("{[", "]}").
-		Option("missingkey=zero")
-	if tmplt, err = tmplt.Funcs(sprig.FuncMap()).Parse(string(tmplData)); err != nil {
+		New(name).
+		Delims(header.LeftDelim, header.RightDelim).
+		Option(missingKey)
+	if tmplt, err = tmplt.Funcs(funcs).Parse(string(tmplData)); err != nil {
 		err = fmt.Errorf("error parsing template: %v\n", err)
 		return
 	}
+	err = escapeTemplate(tmplt, header.Escape)
 	return
 }
 
-// Can't believe something like this doesn't exist already...
-func getEnvMap() lib.TemplateData {
+// getEnvMap builds the template data from the process environment and the env files passed on the
+// command line, layered according to cf.EnvFileOverride. The references on the values are then
+// expanded (see lib.ExpandVariables). It fails if any variable cannot be expanded, since a template
+// can reach any variable (through Filter or range, for example) and would render its raw value.
+func getEnvMap(cf commandlineFlags) (lib.TemplateData, error) {
 	envAssignments := os.Environ()
-	envMap := make(map[string]templateUtils.ExtendedString, len(envAssignments))
-	rexp, _ := regexp.Compile(`%(?P<VARNAME>[\w-]+)%`)
-	for _, envAssignment := range os.Environ() {
+	rawEnv := make(map[string]string, len(envAssignments))
+	declared := make([]string, 0, len(envAssignments))
+	for _, envAssignment := range envAssignments {
 		envVar := strings.SplitN(envAssignment, "=", 2)
-		envVar[1] = os.ExpandEnv(string(rexp.ReplaceAll([]byte(envVar[1]), []byte(`${$VARNAME}`))))
-		envMap[envVar[0]] = templateUtils.ExtendedString(envVar[1])
+		rawEnv[envVar[0]] = envVar[1]
+		declared = append(declared, envVar[0])
+	}
+
+	// The values from the env files are already expanded by the dotenv parser, but for their late
+	// references
+	expandOptions := lib.ExpandOptions{Literal: map[string]bool{}, LateOnly: map[string]bool{}}
+	for _, envFile := range cf.EnvFiles {
+		vars, err := loadEnvFile(envFile, rawEnv, cf.EnvFileOverride)
+		if err != nil {
+			return nil, err
+		}
+		for _, envVar := range vars {
+			declared = append(declared, envVar.Name)
+			if _, inEnv := os.LookupEnv(envVar.Name); inEnv && !cf.EnvFileOverride {
+				continue
+			}
+			rawEnv[envVar.Name] = envVar.Value
+			expandOptions.Literal[envVar.Name] = envVar.Literal
+			expandOptions.LateOnly[envVar.Name] = true
+		}
+	}
+
+	var err error
+	if expandOptions.Only, err = compileNamePatterns(cf.ExpandOnly); err != nil {
+		return nil, err
+	}
+	if expandOptions.Skip, err = compileNamePatterns(cf.NoExpand); err != nil {
+		return nil, err
+	}
+	lib.SetDeclarationOrder(declared)
+	expandedEnv, expandErrors := lib.ExpandVariables(rawEnv, expandOptions)
+	if expandErrors != nil {
+		return nil, fmt.Errorf("%v\nVariables that must not be expanded can be excluded with -no-expand", expandErrors)
+	}
+	envMap := make(lib.TemplateData, len(expandedEnv))
+	for name, value := range expandedEnv {
+		envMap[name] = templateUtils.ExtendedString(value)
+	}
+	return envMap, nil
+}
+
+// compileNamePatterns returns a single expression that matches the names that fully match any of
+// patterns, or nil if there are no patterns
+func compileNamePatterns(patterns []string) (*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid variable name pattern %s: %v", pattern, err)
+		}
 	}
-	return envMap
+	return regexp.Compile("^(?:(?:" + strings.Join(patterns, ")|(?:") + "))$")
+}
+
+// loadEnvFile parses the dotenv file fileName. References on it are resolved against env. Unless
+// override is set, the references to the variables of the process environment resolve to their
+// environment value even if the file defines them, since that's the value that is used
+func loadEnvFile(fileName string, env map[string]string, override bool) ([]lib.EnvVar, error) {
+	envFile, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open env file %s: %v", fileName, err)
+	}
+	defer func() { _ = envFile.Close() }()
+	var protected func(string) (string, bool)
+	if !override {
+		protected = os.LookupEnv
+	}
+	return lib.ParseDotenv(envFile, fileName, func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}, protected)
+}
+
+// loadDataFiles parses the name=path data files passed on the command line, and mounts them on the
+// template data
+func loadDataFiles(dataFiles []string) error {
+	for _, dataFile := range dataFiles {
+		name, path, found := strings.Cut(dataFile, "=")
+		if !found || len(name) == 0 || len(path) == 0 {
+			return fmt.Errorf("invalid data file %q. The expected format is name=path", dataFile)
+		}
+		format, err := utils.FormatFromPath(path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot read data file %s: %v", path, err)
+		}
+		value, err := utils.DecodeStructured(format, content)
+		if err != nil {
+			return fmt.Errorf("cannot parse data file %s: %v", path, err)
+		}
+		lib.MountData(name, value)
+	}
+	return nil
 }
 
 func main() {
 	defaultFlags := commandlineFlags{
-		InputFile:  "",
-		OutputFile: "",
+		InputFile:       "",
+		OutputFile:      "",
+		InputDir:        "",
+		OutputDir:       "",
+		TemplateSuffix:  "",
+		LeftDelim:       "{[",
+		RightDelim:      "]}",
+		Strict:          false,
+		EnvFiles:        nil,
+		EnvFileOverride: false,
+		NoExpand:        nil,
+		ExpandOnly:      nil,
+		CertMinValidity: 0,
+		AutoEscape:      false,
+		FileRoots:       nil,
+		DataFiles:       nil,
 	}
 	outputFlags := commandlineFlags{}
 	if err := utils.DefineCommandLineFlags(&outputFlags, defaultFlags); err != nil {
@@ -88,7 +251,36 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := tmplt.Execute(outputFile, getEnvMap()); err != nil {
+	templateUtils.SetCertMinValidity(outputFlags.CertMinValidity)
+	if err := templateUtils.SetFileRoots(outputFlags.FileRoots); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error in options: %v\n", err)
+		os.Exit(1)
+	}
+
+	envMap, err := getEnvMap(outputFlags)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error loading the environment: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := loadDataFiles(outputFlags.DataFiles); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error loading data files: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Every file loaded by the templates is read once, and all the templates of a directory share
+	// the same content
+	templateUtils.ResetFileCache()
+
+	if len(outputFlags.InputDir) > 0 {
+		if err := renderDirectory(outputFlags, envMap); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Error generating files: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if err := tmplt.Execute(outputFile, envMap); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error generating file: %v\n", err)
 		os.Exit(1)
 	}
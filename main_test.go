@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetEnvMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		envFile  string
+		override bool
+		want     map[string]string
+	}{
+		{
+			name:    "Quoted values are not expanded again",
+			envFile: "LOG_PATTERN='%d [%t] $literal'\n" + `PRICE="costs \$5 and \$HOME"` + "\nHOME_DIR=$HOME\n",
+			want: map[string]string{
+				"LOG_PATTERN": "%d [%t] $literal",
+				"PRICE":       "costs $5 and $HOME",
+				"HOME_DIR":    "/h",
+			},
+		},
+		{
+			name:    "Late references on env files",
+			envFile: "FALLBACK=%MISSING:-default%\nCOPY=\"%PRICE%\"\nPRICE='$5 and %%'\nPERCENT=100%%\n",
+			want: map[string]string{
+				"FALLBACK": "default",
+				"COPY":     "$5 and %%",
+				"PRICE":    "$5 and %%",
+				"PERCENT":  "100%",
+			},
+		},
+		{
+			name:    "Environment takes precedence",
+			envFile: "TEST_ENV_VALUE='from $file'\n",
+			want:    map[string]string{"TEST_ENV_VALUE": "from /h"},
+		},
+		{
+			name:     "Env file overrides the environment",
+			envFile:  "TEST_ENV_VALUE='from $file'\n",
+			override: true,
+			want:     map[string]string{"TEST_ENV_VALUE": "from $file"},
+		},
+		{
+			name:    "References resolve to the environment when it takes precedence",
+			envFile: "TEST_ENV_HOST=localhost\nURL=http://${TEST_ENV_HOST}:80\n",
+			want:    map[string]string{"TEST_ENV_HOST": "prod", "URL": "http://prod:80"},
+		},
+		{
+			name:     "References resolve to the env file when it overrides the environment",
+			envFile:  "TEST_ENV_HOST=localhost\nURL=http://${TEST_ENV_HOST}:80\n",
+			override: true,
+			want:     map[string]string{"TEST_ENV_HOST": "localhost", "URL": "http://localhost:80"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", "/h")
+			t.Setenv("TEST_ENV_VALUE", "from $HOME")
+			t.Setenv("TEST_ENV_HOST", "prod")
+			envFile := filepath.Join(t.TempDir(), "test.env")
+			if err := os.WriteFile(envFile, []byte(tt.envFile), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := getEnvMap(commandlineFlags{EnvFiles: []string{envFile}, EnvFileOverride: tt.override})
+			if err != nil {
+				t.Fatalf("getEnvMap() error = %v", err)
+			}
+			for name, want := range tt.want {
+				if string(got[name]) != want {
+					t.Errorf("getEnvMap()[%s] = %q, want %q", name, got[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetEnvMapExpandErrors(t *testing.T) {
+	t.Setenv("UNUSED", "x$UNUSED")
+	t.Setenv("REQUIRED", "%MISSING_VALUE:?must be set%")
+	_, err := getEnvMap(commandlineFlags{})
+	if err == nil || !strings.Contains(err.Error(), "cannot expand UNUSED: reference cycle expanding variables: UNUSED -> UNUSED") ||
+		!strings.Contains(err.Error(), "cannot expand REQUIRED: MISSING_VALUE: must be set") {
+		t.Errorf("getEnvMap() error = %v, want the errors of UNUSED and REQUIRED", err)
+	}
+
+	// The variables that are not expanded cannot fail
+	got, err := getEnvMap(commandlineFlags{NoExpand: []string{"UNUSED|REQUIRED"}})
+	if err != nil {
+		t.Fatalf("getEnvMap() error = %v", err)
+	}
+	if got["UNUSED"] != "x$UNUSED" || got["REQUIRED"] != "%MISSING_VALUE:?must be set%" {
+		t.Errorf("getEnvMap() = %q, %q, want the raw values", got["UNUSED"], got["REQUIRED"])
+	}
+}
+
+func TestRequiredReferenceOnDynamicAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "Records",
+			tmpl: `{[range .Records "VAULT_SECRET_" "path;key;destination"]}{[.path]} {[.key]} {[.destination]}{[end]}`,
+			want: "secret/a k d",
+		},
+		{
+			name: "Filter",
+			tmpl: `{[range $k, $v := .Filter "^VAULT_SECRET_"]}{[$k]}={[$v]}{[end]}`,
+			want: "VAULT_SECRET_1=secret/a;k;d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VAULT_SECRET_1", "%SECRET_PATH:?need SECRET_PATH%;k;d")
+			render := func() (string, error) {
+				envMap, err := getEnvMap(commandlineFlags{})
+				if err != nil {
+					return "", err
+				}
+				tmplt, err := newTemplate("test.tmpl", "", []byte(tt.tmpl), commandlineFlags{LeftDelim: "{[", RightDelim: "]}"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				var out strings.Builder
+				err = tmplt.Execute(&out, envMap)
+				return out.String(), err
+			}
+
+			if got, err := render(); err == nil || !strings.Contains(err.Error(), "SECRET_PATH: need SECRET_PATH") {
+				t.Errorf("render = %q, %v, want the :? error", got, err)
+			}
+			t.Setenv("SECRET_PATH", "secret/a")
+			if got, err := render(); err != nil || got != tt.want {
+				t.Errorf("render = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,9 @@
 export GOPRIVATE=github.com/AntonioMA/go-utils
 export TESTVAR_SPC="them are %fight_ti_ng% words"
+export VAULT_SECRET_1="secret/this/is/a/path;whatever;secret/some/path.json"
+export VAULT_SECRET_2_PATH="secret/other/path"
+export VAULT_SECRET_2_KEY="password"
+export VAULT_SECRET_2_DESTINATION="secret/other.json"
 export CONSUL_KV_1="config/whatever;config/config.json"
 export CONSUL_KV_2="config/whatever2;config/config2.json"
 export VAULT_ENVSECRET_2="secret/core/services/sentinel/password\;redisValue\;REDIS_PASS2"
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// strictIndex works like the index builtin, but it fails when a key is not present on the indexed
+// map instead of returning the zero value. It replaces index on strict mode, so lookups on maps
+// (like the ones returned by TemplateData.Filter) cannot silently render an empty string.
+func strictIndex(item reflect.Value, indexes ...reflect.Value) (reflect.Value, error) {
+	item = indirect(item)
+	if !item.IsValid() {
+		return reflect.Value{}, fmt.Errorf("index of untyped nil")
+	}
+	for _, index := range indexes {
+		index = indirect(index)
+		switch item.Kind() {
+		case reflect.Map:
+			if !index.IsValid() || !index.Type().ConvertibleTo(item.Type().Key()) {
+				return reflect.Value{}, fmt.Errorf("invalid key %v for map of type %s", index, item.Type())
+			}
+			value := item.MapIndex(index.Convert(item.Type().Key()))
+			if !value.IsValid() {
+				return reflect.Value{}, fmt.Errorf("map has no entry for key %q", fmt.Sprint(index))
+			}
+			item = value
+		case reflect.Array, reflect.Slice, reflect.String:
+			if !index.IsValid() || !index.CanInt() {
+				return reflect.Value{}, fmt.Errorf("cannot index %s with %v", item.Type(), index)
+			}
+			if i := index.Int(); i < 0 || int(i) >= item.Len() {
+				return reflect.Value{}, fmt.Errorf("index out of range: %d", i)
+			}
+			item = item.Index(int(index.Int()))
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot index item of type %s", item.Type())
+		}
+		item = indirect(item)
+	}
+	return item, nil
+}
+
+// indirect returns the value pointed to or wrapped by v
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
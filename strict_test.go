@@ -0,0 +1,135 @@
+package main
+
+import (
+	"envtemplate/lib"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStrictIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    any
+		indexes []any
+		want    any
+		wantErr string
+	}{
+		{
+			name:    "Existing map key",
+			item:    lib.TemplateData{"A": "a"},
+			indexes: []any{"A"},
+			want:    "a",
+		},
+		{
+			name:    "Missing map key",
+			item:    lib.TemplateData{"A": "a"},
+			indexes: []any{"B"},
+			wantErr: `map has no entry for key "B"`,
+		},
+		{
+			name:    "Nested lookups",
+			item:    map[string]any{"a": []any{"x", map[string]int{"b": 2}}},
+			indexes: []any{"a", 1, "b"},
+			want:    2,
+		},
+		{
+			name:    "Slice index",
+			item:    []string{"x", "y"},
+			indexes: []any{1},
+			want:    "y",
+		},
+		{
+			name:    "Slice index out of range",
+			item:    []string{"x", "y"},
+			indexes: []any{2},
+			wantErr: "index out of range: 2",
+		},
+		{
+			name:    "Negative slice index",
+			item:    []string{"x", "y"},
+			indexes: []any{-1},
+			wantErr: "index out of range: -1",
+		},
+		{
+			name:    "Invalid key type",
+			item:    []string{"x"},
+			indexes: []any{"a"},
+			wantErr: "cannot index",
+		},
+		{
+			name:    "Untyped nil",
+			item:    nil,
+			indexes: []any{"a"},
+			wantErr: "index of untyped nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexes := make([]reflect.Value, len(tt.indexes))
+			for i, index := range tt.indexes {
+				indexes[i] = reflect.ValueOf(index)
+			}
+			got, err := strictIndex(reflect.ValueOf(tt.item), indexes...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("strictIndex() error = %v, wantErr %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("strictIndex() unexpected error = %v", err)
+			}
+			// Compared as strings, since TemplateData values are ExtendedString
+			if fmt.Sprint(got.Interface()) != fmt.Sprint(tt.want) {
+				t.Errorf("strictIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "Existing variable",
+			tmpl: "{[.NAME]}",
+			want: "service",
+		},
+		{
+			name:    "Missing variable",
+			tmpl:    "line 1\n{[.NAM]}",
+			wantErr: `test.tmpl:2:2: executing "test.tmpl" at <.NAM>: map has no entry for key "NAM"`,
+		},
+		{
+			name:    "Missing key on a Filter result",
+			tmpl:    `{[index (.Filter "^NAME$") "OTHER"]}`,
+			wantErr: `map has no entry for key "OTHER"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := commandlineFlags{LeftDelim: "{[", RightDelim: "]}", Strict: true}
+			tmplt, err := newTemplate("test.tmpl", "", []byte(tt.tmpl), cf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			err = tmplt.Execute(&out, lib.TemplateData{"NAME": "service"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, wantErr %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || out.String() != tt.want {
+				t.Errorf("Execute() = %q, %v, want %q", out.String(), err, tt.want)
+			}
+		})
+	}
+}
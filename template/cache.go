@@ -0,0 +1,199 @@
+package template
+
+import (
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// cachedFile is the content of a file loaded during the current render. A file can be loaded
+// as is, or encoded (by LoadFileBase64 or LoadFileHex) without keeping its raw content
+type cachedFile struct {
+	loaded  bool
+	content ExtendedString
+	// encoded maps the name of each encoding (see fileEncodings) to the file encoded with it
+	encoded map[string]ExtendedString
+	// err is the error found loading the file (for example, because it does not exist), if any
+	err error
+}
+
+// cachedListing is the result of a LoadGlob or LoadDir call made during the current render
+type cachedListing struct {
+	files []LoadedFile
+	err   error
+}
+
+// fileCache holds the files loaded during the current render, by their resolved absolute path (see
+// resolvePath), so each file is read only once and every load sees the same content. The files
+// that could not be loaded are kept too, so they keep failing, and so are the LoadGlob and LoadDir
+// results, so they keep listing the same files
+var fileCache = struct {
+	sync.Mutex
+	files    map[string]*cachedFile
+	listings map[string]cachedListing
+	// sources holds the name of the file each loaded value comes from (see cachedFileName)
+	sources map[stringID]string
+}{files: map[string]*cachedFile{}, listings: map[string]cachedListing{}, sources: map[stringID]string{}}
+
+// stringID identifies a string value by its data, instead of by its content, so two values with
+// the same content (as two identical files, or a file and an environment variable) are different
+type stringID struct {
+	data *byte
+	len  int
+}
+
+func idOf(s ExtendedString) stringID {
+	return stringID{data: unsafe.StringData(string(s)), len: len(s)}
+}
+
+// ResetFileCache forgets all the files loaded until now, so they are read again the next time
+// they're loaded. It should be called once before every render: the files are cached for the
+// whole render (even if it spans several templates), so all of them see the same content even if
+// a file changes in the middle of it
+func ResetFileCache() {
+	fileCache.Lock()
+	defer fileCache.Unlock()
+	fileCache.files = map[string]*cachedFile{}
+	fileCache.listings = map[string]cachedListing{}
+	fileCache.sources = map[stringID]string{}
+}
+
+// cachedFileName returns the name of the file es was loaded from, if es is the value returned by
+// any of the Load methods during the current render. Only that value (or a copy of it) is
+// recognized, not any other string that happens to have the same content
+func cachedFileName(es ExtendedString) (string, bool) {
+	if len(es) == 0 {
+		return "", false
+	}
+	fileCache.Lock()
+	defer fileCache.Unlock()
+	name, ok := fileCache.sources[idOf(es)]
+	return name, ok
+}
+
+// rememberSource records that es was loaded from fileName. fileCache must be locked
+func rememberSource(es ExtendedString, fileName string) {
+	if len(es) > 0 {
+		fileCache.sources[idOf(es)] = fileName
+	}
+}
+
+// loadedString converts data to a string that does not share its memory with any other string, as
+// the one byte strings built by the runtime do, so it can be identified by rememberSource
+func loadedString(data []byte) ExtendedString {
+	if len(data) == 1 {
+		return ExtendedString(strings.Clone(string(data)))
+	}
+	return ExtendedString(data)
+}
+
+// cachedContent returns the content of the file at path, loaded as fileName. If it is not in the
+// cache yet, it is rebuilt from any encoded copy of the file that is, or read with read otherwise.
+// In both cases the content is only cached if validate accepts it, and the error is cached instead
+// otherwise
+func cachedContent(path, fileName string, read func() ([]byte, error), validate func([]byte) error) (ExtendedString, error) {
+	fileCache.Lock()
+	defer fileCache.Unlock()
+
+	entry := fileCache.files[path]
+	if entry != nil && entry.err != nil {
+		return "", entry.err
+	}
+	if entry != nil && entry.loaded {
+		return entry.content, nil
+	}
+	var content []byte
+	var err error
+	if entry != nil {
+		for name, encoded := range entry.encoded {
+			content, err = fileEncodings[name].decode(string(encoded))
+			break
+		}
+	} else {
+		content, err = read()
+	}
+	if err == nil {
+		err = validate(content)
+	}
+	if entry == nil {
+		entry = &cachedFile{}
+		fileCache.files[path] = entry
+	}
+	if err != nil {
+		entry.err = err
+		return "", err
+	}
+	entry.loaded, entry.content = true, loadedString(content)
+	rememberSource(entry.content, fileName)
+	return entry.content, nil
+}
+
+// cachedEncoding returns the content of the file at path (loaded as fileName) encoded with the
+// encoding name. If it is not in the cache yet, it is encoded from the cached content, or with
+// encode (that reads the file) if the file was not loaded yet. As with cachedContent, the errors are
+// cached too
+func cachedEncoding(path, fileName, name string, encode func() (ExtendedString, error)) (ExtendedString, error) {
+	fileCache.Lock()
+	defer fileCache.Unlock()
+
+	entry := fileCache.files[path]
+	if entry != nil && entry.err != nil {
+		return "", entry.err
+	}
+	if entry != nil {
+		if encoded, ok := entry.encoded[name]; ok {
+			return encoded, nil
+		}
+	}
+	var encoded ExtendedString
+	switch {
+	case entry != nil && entry.loaded:
+		encoded = fileEncodings[name].encode([]byte(entry.content))
+	case entry != nil:
+		// The file was only loaded with another encoding, which is decoded to get the same content
+		for other, otherEncoded := range entry.encoded {
+			content, err := fileEncodings[other].decode(string(otherEncoded))
+			if err != nil {
+				return "", err
+			}
+			encoded = fileEncodings[name].encode(content)
+			break
+		}
+	default:
+		var err error
+		encoded, err = encode()
+		entry = &cachedFile{err: err}
+		fileCache.files[path] = entry
+		if err != nil {
+			return "", err
+		}
+	}
+	if entry.encoded == nil {
+		entry.encoded = map[string]ExtendedString{}
+	}
+	entry.encoded[name] = encoded
+	rememberSource(encoded, fileName)
+	return encoded, nil
+}
+
+// cachedFiles returns the files listed by the LoadGlob or LoadDir call identified by key. If it
+// is not in the cache yet, the files are listed (and loaded) with list, and the result (even if
+// it's an error) is cached
+func cachedFiles(key string, list func() ([]LoadedFile, error)) ([]LoadedFile, error) {
+	fileCache.Lock()
+	listing, found := fileCache.listings[key]
+	fileCache.Unlock()
+	if found {
+		return listing.files, listing.err
+	}
+
+	// The cache is not locked while listing, since loading each file locks it
+	files, err := list()
+	fileCache.Lock()
+	defer fileCache.Unlock()
+	if listing, found := fileCache.listings[key]; found {
+		return listing.files, listing.err
+	}
+	fileCache.listings[key] = cachedListing{files: files, err: err}
+	return files, err
+}
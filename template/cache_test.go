@@ -0,0 +1,189 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFileCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		first func(es ExtendedString) (ExtendedString, error)
+		then  func(es ExtendedString) (ExtendedString, error)
+		want  ExtendedString
+	}{
+		{
+			name:  "Same loader",
+			first: ExtendedString.LoadFile,
+			then:  ExtendedString.LoadFile,
+			want:  "original",
+		},
+		{
+			name:  "Relative loader",
+			first: ExtendedString.LoadFile,
+			then: func(es ExtendedString) (ExtendedString, error) {
+				return ExtendedString(filepath.Base(string(es))).LoadRelativeFile(filepath.Dir(string(es)))
+			},
+			want: "original",
+		},
+		{
+			name:  "Encoded after raw",
+			first: ExtendedString.LoadFileRaw,
+			then:  ExtendedString.LoadFileBase64,
+			want:  "b3JpZ2luYWw=",
+		},
+		{
+			name:  "Raw after encoded",
+			first: ExtendedString.LoadFileHex,
+			then:  ExtendedString.LoadFile,
+			want:  "original",
+		},
+		{
+			name:  "Encoding after another encoding",
+			first: ExtendedString.LoadFileHex,
+			then:  ExtendedString.LoadFileBase64,
+			want:  "b3JpZ2luYWw=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetFileCache()
+			fileName := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(fileName, []byte("original"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tt.first(ExtendedString(fileName)); err != nil {
+				t.Fatalf("first load error = %v", err)
+			}
+			if err := os.WriteFile(fileName, []byte("changed"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := tt.then(ExtendedString(fileName)); err != nil || got != tt.want {
+				t.Errorf("second load = %q, %v, want %q", got, err, tt.want)
+			}
+
+			ResetFileCache()
+			if got, err := ExtendedString(fileName).LoadFile(); err != nil || got != "changed" {
+				t.Errorf("load after ResetFileCache() = %q, %v, want %q", got, err, "changed")
+			}
+		})
+	}
+}
+
+func TestExtendedString_source(t *testing.T) {
+	ResetFileCache()
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{broken"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "d.json"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	load := func(name string) ExtendedString {
+		content, err := ExtendedString(filepath.Join(dir, name)).LoadFile()
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+		return content
+	}
+	a, b, d := load("a.json"), load("b.json"), load("d.json")
+	encoded, err := ExtendedString(filepath.Join(dir, "c.json")).LoadFileBase64()
+	if err != nil {
+		t.Fatalf("LoadFileBase64() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		es   ExtendedString
+		want string
+	}{
+		{name: "Loaded file", es: a, want: "file " + filepath.Join(dir, "a.json")},
+		{name: "File with the same content", es: b, want: "file " + filepath.Join(dir, "b.json")},
+		{name: "Loaded again", es: load("b.json"), want: "file " + filepath.Join(dir, "b.json")},
+		{name: "Single byte file", es: d, want: "file " + filepath.Join(dir, "d.json")},
+		{name: "Encoded file", es: encoded, want: "file " + filepath.Join(dir, "c.json")},
+		{name: "Value with the same content", es: ExtendedString(strings.Clone("{broken")), want: "value"},
+		{name: "Single byte value", es: "x", want: "value"},
+		{name: "Empty value", es: "", want: "value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.es.source(); got != tt.want {
+				t.Errorf("source() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := b.ParseJSON(); err == nil || !strings.Contains(err.Error(), "cannot parse file "+filepath.Join(dir, "b.json")) {
+		t.Errorf("ParseJSON() error = %v, want it to name b.json", err)
+	}
+
+	// The files loaded on a previous render are not reported any more
+	ResetFileCache()
+	if got := a.source(); got != "value" {
+		t.Errorf("source() after ResetFileCache() = %q, want %q", got, "value")
+	}
+}
+
+func TestFileCacheMissingFile(t *testing.T) {
+	ResetFileCache()
+	fileName := filepath.Join(t.TempDir(), "file.txt")
+	if got, err := ExtendedString(fileName).LoadFileIfExists(); err != nil || got != "" {
+		t.Fatalf("LoadFileIfExists() = %q, %v, want an empty string", got, err)
+	}
+	if err := os.WriteFile(fileName, []byte("created"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The file did not exist when it was first loaded, so it does not for the rest of the render
+	if got, err := ExtendedString(fileName).LoadFile(); err == nil {
+		t.Errorf("LoadFile() = %q, want an error", got)
+	}
+	if got, err := ExtendedString(fileName).LoadFileBase64(); err == nil {
+		t.Errorf("LoadFileBase64() = %q, want an error", got)
+	}
+	if got, err := ExtendedString(fileName).LoadFileIfExists(); err != nil || got != "" {
+		t.Errorf("LoadFileIfExists() = %q, %v, want an empty string", got, err)
+	}
+
+	ResetFileCache()
+	if got, err := ExtendedString(fileName).LoadFile(); err != nil || got != "created" {
+		t.Errorf("load after ResetFileCache() = %q, %v, want %q", got, err, "created")
+	}
+}
+
+func TestFileCacheListings(t *testing.T) {
+	ResetFileCache()
+	t.Chdir(makeTree(t, "a.conf"))
+	check := func(wantGlob, wantMissing, wantDir []string) {
+		t.Helper()
+		if got, err := ExtendedString("*.conf").LoadGlob(); err != nil || !reflect.DeepEqual(fileNames(got), wantGlob) {
+			t.Errorf("LoadGlob() = %v, %v, want %v", fileNames(got), err, wantGlob)
+		}
+		if got, err := ExtendedString("missing/*.conf").LoadGlob(); err != nil || !reflect.DeepEqual(fileNames(got), wantMissing) {
+			t.Errorf("LoadGlob() = %v, %v, want %v", fileNames(got), err, wantMissing)
+		}
+		if got, err := ExtendedString(".").LoadDir(); err != nil || !reflect.DeepEqual(fileNames(got), wantDir) {
+			t.Errorf("LoadDir() = %v, %v, want %v", fileNames(got), err, wantDir)
+		}
+	}
+	check([]string{"a.conf"}, []string{}, []string{"a.conf"})
+
+	for _, name := range []string{"b.conf", "missing/c.conf"} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The files created in the middle of the render are not listed
+	check([]string{"a.conf"}, []string{}, []string{"a.conf"})
+
+	ResetFileCache()
+	check([]string{"a.conf", "b.conf"}, []string{"missing/c.conf"}, []string{"a.conf", "b.conf"})
+}
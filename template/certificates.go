@@ -0,0 +1,189 @@
+package template
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// certMinValidity is the minimum time any loaded certificate must still be valid for. 0 disables
+// the check
+var certMinValidity time.Duration
+
+// SetCertMinValidity makes loading or parsing a certificate that expires in less than minValidity
+// (or that is not valid yet) fail. Passing 0 disables the check
+func SetCertMinValidity(minValidity time.Duration) {
+	certMinValidity = minValidity
+}
+
+// PEMBlock is a single block of a PEM encoded bundle
+type PEMBlock struct {
+	Type    string
+	Headers map[string]string
+	Bytes   []byte
+}
+
+// String returns the block PEM encoded
+func (b PEMBlock) String() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: b.Type, Headers: b.Headers, Bytes: b.Bytes}))
+}
+
+// PEM returns the block PEM encoded, as an ExtendedString
+func (b PEMBlock) PEM() ExtendedString {
+	return ExtendedString(b.String())
+}
+
+// Certificate holds the most relevant information of a X.509 certificate
+type Certificate struct {
+	Subject           string
+	Issuer            string
+	SerialNumber      string
+	DNSNames          []string
+	IPAddresses       []string
+	EmailAddresses    []string
+	URIs              []string
+	NotBefore         time.Time
+	NotAfter          time.Time
+	IsCA              bool
+	SHA256Fingerprint string // Uppercase, colon separated hex, as openssl prints it
+	SHA1Fingerprint   string // Uppercase, colon separated hex, as openssl prints it
+	Raw               []byte // DER encoded certificate
+}
+
+// SANs returns all the subject alternative names of the certificate: DNS names, IP addresses,
+// email addresses and URIs
+func (c Certificate) SANs() []string {
+	rv := make([]string, 0, len(c.DNSNames)+len(c.IPAddresses)+len(c.EmailAddresses)+len(c.URIs))
+	rv = append(rv, c.DNSNames...)
+	rv = append(rv, c.IPAddresses...)
+	rv = append(rv, c.EmailAddresses...)
+	return append(rv, c.URIs...)
+}
+
+// PEM returns the certificate PEM encoded
+func (c Certificate) PEM() ExtendedString {
+	return PEMBlock{Type: "CERTIFICATE", Bytes: c.Raw}.PEM()
+}
+
+// ParsePEM splits es, which must be PEM encoded, into its blocks. It returns an error if es has no
+// PEM blocks
+func (es ExtendedString) ParsePEM() ([]PEMBlock, error) {
+	var rv []PEMBlock
+	rest := []byte(es)
+	for {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		rv = append(rv, PEMBlock{Type: block.Type, Headers: block.Headers, Bytes: block.Bytes})
+	}
+	if len(rv) == 0 {
+		return nil, fmt.Errorf("no PEM data found on %s", es.source())
+	}
+	return rv, nil
+}
+
+// ParseCertificates returns all the certificates of the PEM encoded es, in order. Blocks that are
+// not certificates (like keys) are ignored, but it returns an error if there are no certificates
+func (es ExtendedString) ParseCertificates() ([]Certificate, error) {
+	blocks, err := es.ParsePEM()
+	if err != nil {
+		return nil, err
+	}
+	var rv []Certificate
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := parseCertificate(block.Bytes, es.source())
+		if err != nil {
+			return nil, err
+		}
+		rv = append(rv, cert)
+	}
+	if len(rv) == 0 {
+		return nil, fmt.Errorf("no certificates found on %s", es.source())
+	}
+	return rv, nil
+}
+
+// ParseCertificate returns the first certificate of the PEM encoded es
+func (es ExtendedString) ParseCertificate() (Certificate, error) {
+	certs, err := es.ParseCertificates()
+	if err != nil {
+		return Certificate{}, err
+	}
+	return certs[0], nil
+}
+
+// checkCertificates fails if any certificate in the PEM encoded data is not valid for at least
+// certMinValidity
+func checkCertificates(data []byte, source string) error {
+	if certMinValidity == 0 {
+		return nil
+	}
+	for rest := data; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			return nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := parseCertificate(block.Bytes, source); err != nil {
+			return err
+		}
+	}
+}
+
+// parseCertificate parses the DER encoded certificate der, checking its validity if needed
+func parseCertificate(der []byte, source string) (Certificate, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("invalid certificate on %s: %v", source, err)
+	}
+	if certMinValidity > 0 {
+		now := time.Now()
+		if now.Before(cert.NotBefore) {
+			return Certificate{}, fmt.Errorf("certificate %s on %s is not valid until %s", cert.Subject, source, cert.NotBefore)
+		}
+		if now.Add(certMinValidity).After(cert.NotAfter) {
+			return Certificate{}, fmt.Errorf("certificate %s on %s expires at %s, in less than %s", cert.Subject, source, cert.NotAfter, certMinValidity)
+		}
+	}
+
+	sha256Sum, sha1Sum := sha256.Sum256(cert.Raw), sha1.Sum(cert.Raw) //nolint:gosec
+	rv := Certificate{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		SerialNumber:      cert.SerialNumber.String(),
+		DNSNames:          cert.DNSNames,
+		EmailAddresses:    cert.EmailAddresses,
+		NotBefore:         cert.NotBefore,
+		NotAfter:          cert.NotAfter,
+		IsCA:              cert.IsCA,
+		SHA256Fingerprint: fingerprint(sha256Sum[:]),
+		SHA1Fingerprint:   fingerprint(sha1Sum[:]),
+		Raw:               cert.Raw,
+	}
+	for _, ip := range cert.IPAddresses {
+		rv.IPAddresses = append(rv.IPAddresses, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		rv.URIs = append(rv.URIs, uri.String())
+	}
+	return rv, nil
+}
+
+// fingerprint formats sum as uppercase colon separated hex
+func fingerprint(sum []byte) string {
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
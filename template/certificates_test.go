@@ -0,0 +1,118 @@
+package template
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCertificate returns a PEM encoded self-signed certificate valid until notAfter
+func newTestCertificate(t *testing.T, notAfter time.Time) ExtendedString {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "test.example.com"},
+		DNSNames:     []string{"test.example.com", "www.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ExtendedString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestExtendedString_ParsePEM(t *testing.T) {
+	bundle := mustLoad("./test/cert.pem") + "\n" + ExtendedString(PEMBlock{Type: "PRIVATE KEY", Bytes: []byte("key")}.String())
+	blocks, err := bundle.ParsePEM()
+	if err != nil {
+		t.Fatalf("ParsePEM() unexpected error = %v", err)
+	}
+	if len(blocks) != 2 || blocks[0].Type != "CERTIFICATE" || blocks[1].Type != "PRIVATE KEY" {
+		t.Errorf("ParsePEM() = %v", blocks)
+	}
+	if _, err := ExtendedString("not PEM").ParsePEM(); err == nil {
+		t.Errorf("ParsePEM() should fail if there is no PEM data")
+	}
+}
+
+func TestExtendedString_ParseCertificate(t *testing.T) {
+	cert, err := mustLoad("./test/cert.pem").ParseCertificate()
+	if err != nil {
+		t.Fatalf("ParseCertificate() unexpected error = %v", err)
+	}
+	if cert.Subject != "CN=*.google.com" ||
+		cert.Issuer != "CN=GTS CA 1C3,O=Google Trust Services LLC,C=US" ||
+		!cert.NotAfter.Equal(time.Date(2022, 1, 24, 2, 19, 51, 0, time.UTC)) ||
+		cert.SHA256Fingerprint != "E9:7C:86:18:34:DE:F4:11:4D:2D:5E:6F:1A:49:22:A1:04:EE:9E:7C:8D:CB:72:3F:6D:67:58:8F:7E:F3:4B:AB" {
+		t.Errorf("ParseCertificate() = %+v", cert)
+	}
+	if cert.PEM() != mustLoad("./test/cert.pem") {
+		t.Errorf("PEM() = %v", cert.PEM())
+	}
+
+	generated, err := newTestCertificate(t, time.Now().Add(time.Hour)).ParseCertificate()
+	if err != nil {
+		t.Fatalf("ParseCertificate() unexpected error = %v", err)
+	}
+	if want := []string{"test.example.com", "www.example.com", "10.0.0.1"}; !reflect.DeepEqual(generated.SANs(), want) {
+		t.Errorf("SANs() = %v, want %v", generated.SANs(), want)
+	}
+
+	if _, err := ExtendedString(PEMBlock{Type: "PRIVATE KEY", Bytes: []byte("key")}.String()).ParseCertificate(); err == nil {
+		t.Errorf("ParseCertificate() should fail if there are no certificates")
+	}
+}
+
+func TestCertMinValidity(t *testing.T) {
+	SetCertMinValidity(24 * time.Hour)
+	defer SetCertMinValidity(0)
+
+	if _, err := newTestCertificate(t, time.Now().Add(48*time.Hour)).ParseCertificate(); err != nil {
+		t.Errorf("ParseCertificate() unexpected error = %v", err)
+	}
+	if _, err := newTestCertificate(t, time.Now().Add(time.Hour)).ParseCertificate(); err == nil ||
+		!strings.Contains(err.Error(), "in less than 24h0m0s") {
+		t.Errorf("ParseCertificate() expected a validity error, got %v", err)
+	}
+
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, []byte(newTestCertificate(t, time.Now().Add(time.Hour))), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	validFile := filepath.Join(t.TempDir(), "valid.pem")
+	if err := os.WriteFile(validFile, []byte(newTestCertificate(t, time.Now().Add(48*time.Hour))), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaders := map[string]func(ExtendedString) (ExtendedString, error){
+		"LoadFile":       ExtendedString.LoadFile,
+		"LoadFileRaw":    ExtendedString.LoadFileRaw,
+		"LoadFileBase64": ExtendedString.LoadFileBase64,
+		"LoadFileHex":    ExtendedString.LoadFileHex,
+	}
+	for name, load := range loaders {
+		ResetFileCache()
+		if _, err := load(ExtendedString(certFile)); err == nil || !strings.Contains(err.Error(), certFile) {
+			t.Errorf("%s() expected a validity error, got %v", name, err)
+		}
+		if _, err := load(ExtendedString(validFile)); err != nil {
+			t.Errorf("%s() unexpected error = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+)
+
+// ToBase64URL returns the es string converted to Base64, using the URL and file name safe encoding
+func (es ExtendedString) ToBase64URL() ExtendedString {
+	return ExtendedString(base64.URLEncoding.EncodeToString([]byte(es)))
+}
+
+// FromBase64 decodes es, which must be encoded with the standard (padded) Base64 encoding
+func (es ExtendedString) FromBase64() (ExtendedString, error) {
+	return es.fromBase64(base64.StdEncoding, "standard")
+}
+
+// FromBase64URL decodes es, which must be encoded with the URL safe (padded) Base64 encoding
+func (es ExtendedString) FromBase64URL() (ExtendedString, error) {
+	return es.fromBase64(base64.URLEncoding, "URL")
+}
+
+// FromBase64Raw decodes es, which must be encoded with the standard Base64 encoding, without padding
+func (es ExtendedString) FromBase64Raw() (ExtendedString, error) {
+	return es.fromBase64(base64.RawStdEncoding, "raw standard")
+}
+
+// FromBase64RawURL decodes es, which must be encoded with the URL safe Base64 encoding, without
+// padding
+func (es ExtendedString) FromBase64RawURL() (ExtendedString, error) {
+	return es.fromBase64(base64.RawURLEncoding, "raw URL")
+}
+
+func (es ExtendedString) fromBase64(encoding *base64.Encoding, name string) (ExtendedString, error) {
+	data, err := encoding.DecodeString(string(es))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s Base64 value on %s: %v", name, es.source(), err)
+	}
+	return ExtendedString(data), nil
+}
+
+// ToHex returns es hex encoded
+func (es ExtendedString) ToHex() ExtendedString {
+	return ExtendedString(hex.EncodeToString([]byte(es)))
+}
+
+// FromHex decodes the hex encoded es
+func (es ExtendedString) FromHex() (ExtendedString, error) {
+	data, err := hex.DecodeString(string(es))
+	if err != nil {
+		return "", fmt.Errorf("invalid hex value on %s: %v", es.source(), err)
+	}
+	return ExtendedString(data), nil
+}
+
+// URLEncode escapes es so it can be safely used as a URL query parameter (or any other URL
+// component, except that spaces are encoded as +)
+func (es ExtendedString) URLEncode() ExtendedString {
+	return ExtendedString(url.QueryEscape(string(es)))
+}
+
+// URLDecode reverts URLEncode, decoding %XX sequences and converting + into spaces
+func (es ExtendedString) URLDecode() (ExtendedString, error) {
+	data, err := url.QueryUnescape(string(es))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL encoded value on %s: %v", es.source(), err)
+	}
+	return ExtendedString(data), nil
+}
@@ -0,0 +1,54 @@
+package template
+
+import "testing"
+
+func TestExtendedString_Encoding(t *testing.T) {
+	es := ExtendedString("a?b/c d>")
+	tests := []struct {
+		name string
+		got  ExtendedString
+		want ExtendedString
+	}{
+		{"ToBase64", es.ToBase64(), "YT9iL2MgZD4="},
+		{"ToBase64URL", es.ToBase64URL(), "YT9iL2MgZD4="},
+		{"ToBase64URL unsafe chars", ExtendedString("??>").ToBase64URL(), "Pz8-"},
+		{"ToHex", es.ToHex(), "613f622f6320643e"},
+		{"URLEncode", es.URLEncode(), "a%3Fb%2Fc+d%3E"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedString_Decoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		decode  func(ExtendedString) (ExtendedString, error)
+		es      ExtendedString
+		want    ExtendedString
+		wantErr bool
+	}{
+		{name: "FromBase64", decode: ExtendedString.FromBase64, es: "YWJjZDEyMzQ=", want: "abcd1234"},
+		{name: "FromBase64 without padding", decode: ExtendedString.FromBase64, es: "YWJjZDEyMzQ", wantErr: true},
+		{name: "FromBase64 invalid", decode: ExtendedString.FromBase64, es: "Pz8-", wantErr: true},
+		{name: "FromBase64URL", decode: ExtendedString.FromBase64URL, es: "Pz8-", want: "??>"},
+		{name: "FromBase64Raw", decode: ExtendedString.FromBase64Raw, es: "YWJjZDEyMzQ", want: "abcd1234"},
+		{name: "FromBase64RawURL", decode: ExtendedString.FromBase64RawURL, es: "Pz8-Pw", want: "??>?"},
+		{name: "FromHex", decode: ExtendedString.FromHex, es: "6162ff", want: "ab\xff"},
+		{name: "FromHex invalid", decode: ExtendedString.FromHex, es: "6g", wantErr: true},
+		{name: "URLDecode", decode: ExtendedString.URLDecode, es: "a%3Fb%2Fc+d%3E", want: "a?b/c d>"},
+		{name: "URLDecode invalid", decode: ExtendedString.URLDecode, es: "a%3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.decode(tt.es)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("%s() = %q, %v, want %q, error: %v", tt.name, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -30,29 +31,47 @@ func (es ExtendedString) Split(sep string) []ExtendedString {
 }
 
 // LoadFile tries loading the file whose name is stored on es and returning the whole content of
-// the file as a string
-func (es ExtendedString) LoadFile() ExtendedString {
-	if fileData, err := os.ReadFile(string(es)); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error reading file %s: %v", es, fileData)
-		return ""
-	} else {
-		return ExtendedString(fileData)
-	}
+// the file as a string. It fails if the file cannot be read, if it is not valid UTF-8 text (see
+// LoadFileRaw), if it is not inside the allowed roots (see SetFileRoots), or if it holds a
+// certificate that does not pass the SetCertMinValidity check
+func (es ExtendedString) LoadFile() (ExtendedString, error) {
+	return readFile(string(es))
+}
+
+// LoadFileRaw works like LoadFile, but it does not check that the file is valid UTF-8 text. Binary
+// content is better loaded with LoadFileBase64 or LoadFileHex, so it's not mangled on the output
+func (es ExtendedString) LoadFileRaw() (ExtendedString, error) {
+	return readRawFile(string(es))
+}
+
+// LoadFileBase64 works like LoadFile, but it returns the content of the file Base64 (standard
+// encoding) encoded, so it can be used for binary files. The file is encoded while it's read
+func (es ExtendedString) LoadFileBase64() (ExtendedString, error) {
+	return encodeFile(string(es), "base64")
+}
+
+// LoadFileHex works like LoadFileBase64, but the content is hex encoded
+func (es ExtendedString) LoadFileHex() (ExtendedString, error) {
+	return encodeFile(string(es), "hex")
+}
+
+// LoadFileOr works like LoadFile, but it returns defaultValue if the file does not exist
+func (es ExtendedString) LoadFileOr(defaultValue ExtendedString) (ExtendedString, error) {
+	return readOptionalFile(string(es), defaultValue)
+}
+
+// LoadFileIfExists works like LoadFile, but it returns an empty string if the file does not exist
+func (es ExtendedString) LoadFileIfExists() (ExtendedString, error) {
+	return readOptionalFile(string(es), "")
 }
 
 // LoadRelativeFile tries loading the file whose name is stored on es, using basePath as the basePath
 // (so es is assumed to be a relative path), and it returns the whole content of
 // the file as a string
-func (es ExtendedString) LoadRelativeFile(basePath string) ExtendedString {
-	fullPath := strings.Join([]string{basePath, string(es)}, string(os.PathSeparator))
-	if fileData, err := os.ReadFile(fullPath); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error reading file %s: %v", fullPath, fileData)
-		return ""
-	} else {
-		return ExtendedString(fileData)
-	}
+func (es ExtendedString) LoadRelativeFile(basePath string) (ExtendedString, error) {
+	return readFile(filepath.Join(basePath, string(es)))
 }
-func (es ExtendedString) LoadRelativeFileES(basePath ExtendedString) ExtendedString {
+func (es ExtendedString) LoadRelativeFileES(basePath ExtendedString) (ExtendedString, error) {
 	return es.LoadRelativeFile(string(basePath))
 }
 
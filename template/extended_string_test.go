@@ -1,6 +1,8 @@
 package template
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -50,8 +52,107 @@ func TestExtendedString_LoadFile(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.es.LoadFile(); got != tt.want {
-				t.Errorf("LoadFile() = %v, want %v", got, tt.want)
+			if got, err := tt.es.LoadFile(); err != nil || got != tt.want {
+				t.Errorf("LoadFile() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedString_LoadFileMissing(t *testing.T) {
+	if got, err := ExtendedString("./test/missing_file.txt").LoadFile(); err == nil {
+		t.Errorf("LoadFile() = %v, want an error", got)
+	}
+	if got, err := ExtendedString("./test/missing_file.txt").LoadRelativeFile("."); err == nil {
+		t.Errorf("LoadRelativeFile() = %v, want an error", got)
+	}
+}
+
+func TestExtendedString_LoadFileBinary(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "keystore.p12")
+	if err := os.WriteFile(binary, []byte{0x30, 0x82, 0xff, 0x00, 'a'}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		es         ExtendedString
+		wantText   ExtendedString
+		wantRaw    ExtendedString
+		wantBase64 ExtendedString
+		wantHex    ExtendedString
+		wantErr    bool
+	}{
+		{
+			name:       "Text file",
+			es:         "./test/sample_file.txt",
+			wantText:   "This is a test file. Do not change me",
+			wantRaw:    "This is a test file. Do not change me",
+			wantBase64: "VGhpcyBpcyBhIHRlc3QgZmlsZS4gRG8gbm90IGNoYW5nZSBtZQ==",
+			wantHex:    "54686973206973206120746573742066696c652e20446f206e6f74206368616e6765206d65",
+		},
+		{
+			name:       "Binary file",
+			es:         ExtendedString(binary),
+			wantErr:    true,
+			wantRaw:    "\x30\x82\xff\x00a",
+			wantBase64: "MIL/AGE=",
+			wantHex:    "3082ff0061",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.es.LoadFile()
+			if (err != nil) != tt.wantErr || got != tt.wantText {
+				t.Errorf("LoadFile() = %q, %v, want %q", got, err, tt.wantText)
+			}
+			if got, err := tt.es.LoadFileRaw(); err != nil || got != tt.wantRaw {
+				t.Errorf("LoadFileRaw() = %q, %v, want %q", got, err, tt.wantRaw)
+			}
+			if got, err := tt.es.LoadFileBase64(); err != nil || got != tt.wantBase64 {
+				t.Errorf("LoadFileBase64() = %q, %v, want %q", got, err, tt.wantBase64)
+			}
+			if got, err := tt.es.LoadFileHex(); err != nil || got != tt.wantHex {
+				t.Errorf("LoadFileHex() = %q, %v, want %q", got, err, tt.wantHex)
+			}
+		})
+	}
+}
+
+func TestExtendedString_LoadFileOr(t *testing.T) {
+	tests := []struct {
+		name         string
+		es           ExtendedString
+		want         ExtendedString
+		wantIfExists ExtendedString
+		wantErr      bool
+	}{
+		{
+			name:         "Existing file",
+			es:           "./test/sample_file.txt",
+			want:         "This is a test file. Do not change me",
+			wantIfExists: "This is a test file. Do not change me",
+		},
+		{
+			name:         "Missing file",
+			es:           "./test/missing_file.txt",
+			want:         "default",
+			wantIfExists: "",
+		},
+		{
+			name:    "Directory",
+			es:      "./test",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.es.LoadFileOr("default")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("LoadFileOr() = %v, %v, want %v", got, err, tt.want)
+			}
+			got, err = tt.es.LoadFileIfExists()
+			if (err != nil) != tt.wantErr || got != tt.wantIfExists {
+				t.Errorf("LoadFileIfExists() = %v, %v, want %v", got, err, tt.wantIfExists)
 			}
 		})
 	}
@@ -78,8 +179,8 @@ func TestExtendedString_LoadRelativeFile(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.es.LoadRelativeFile(tt.args.basePath); got != tt.want {
-				t.Errorf("LoadRelativeFile() = %v, want %v", got, tt.want)
+			if got, err := tt.es.LoadRelativeFile(tt.args.basePath); err != nil || got != tt.want {
+				t.Errorf("LoadRelativeFile() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -106,8 +207,8 @@ func TestExtendedString_LoadRelativeFileES(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.es.LoadRelativeFileES(tt.args.basePath); got != tt.want {
-				t.Errorf("LoadRelativeFileES() = %v, want %v", got, tt.want)
+			if got, err := tt.es.LoadRelativeFileES(tt.args.basePath); err != nil || got != tt.want {
+				t.Errorf("LoadRelativeFileES() = %v, %v, want %v", got, err, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,188 @@
+package template
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// fileRoots are the directories the Load methods can read files from, with their symlinks
+// resolved. If empty, any file can be read
+var fileRoots []string
+
+// SetFileRoots restricts the Load methods to the files inside roots (or any of their
+// subdirectories). Passing no roots lifts the restriction. It fails if a root does not exist
+func SetFileRoots(roots []string) error {
+	resolved := make([]string, 0, len(roots))
+	for _, root := range roots {
+		absRoot, err := filepath.Abs(root)
+		if err == nil {
+			absRoot, err = filepath.EvalSymlinks(absRoot)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid file root %s: %v", root, err)
+		}
+		resolved = append(resolved, absRoot)
+	}
+	fileRoots = resolved
+	return nil
+}
+
+// resolvePath returns the absolute path of fileName, with any symlink and .. resolved, so it's the
+// actual file that will be read. It fails if that file is not inside any of the file roots (see
+// SetFileRoots)
+func resolvePath(fileName string) (string, error) {
+	path, err := filepath.Abs(fileName)
+	if err != nil {
+		return "", fmt.Errorf("invalid file name %s: %v", fileName, err)
+	}
+	// A file that does not exist has no symlinks to resolve, and the clean path is checked instead.
+	// Reading it will fail anyway
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if len(fileRoots) == 0 {
+		return path, nil
+	}
+	for _, root := range fileRoots {
+		if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("access to file %s denied: it's not inside any of the allowed file roots", fileName)
+}
+
+// readFile returns the content of the text file fileName. It fails if the file is not valid UTF-8,
+// and otherwise works like readRawFile
+func readFile(fileName string) (ExtendedString, error) {
+	content, err := readRawFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.ValidString(string(content)) {
+		return "", fmt.Errorf("file %s is not valid UTF-8 text. Binary files can be loaded with LoadFileRaw, LoadFileBase64 or LoadFileHex", fileName)
+	}
+	return content, nil
+}
+
+// readRawFile returns the content of the file fileName. It fails if the file cannot be read, or if
+// it is outside the file roots (see SetFileRoots). Certificates found on the file are checked (see
+// SetCertMinValidity), and an error is returned if any of them does not pass the check. Files are
+// only read the first time they're loaded on a render (see ResetFileCache)
+func readRawFile(fileName string) (ExtendedString, error) {
+	path, err := resolvePath(fileName)
+	if err != nil {
+		return "", err
+	}
+	return cachedContent(path, fileName, func() ([]byte, error) {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read file %s: %w", fileName, err)
+		}
+		return fileData, nil
+	}, func(fileData []byte) error {
+		return checkCertificates(fileData, "file "+fileName)
+	})
+}
+
+// readOptionalFile works like readFile, but it returns defaultValue if fileName does not exist.
+// Any other error (including the file being outside the file roots) is still returned
+func readOptionalFile(fileName string, defaultValue ExtendedString) (ExtendedString, error) {
+	content, err := readFile(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaultValue, nil
+	}
+	return content, err
+}
+
+// fileEncoding is one of the encodings files can be loaded with
+type fileEncoding struct {
+	newEncoder func(w io.Writer) io.WriteCloser
+	// encodedLen returns the length of the encoded content for a given file size
+	encodedLen func(n int) int
+	decode     func(s string) ([]byte, error)
+}
+
+// fileEncodings are the encodings that encodeFile supports, by name
+var fileEncodings = map[string]fileEncoding{
+	"base64": {
+		newEncoder: func(w io.Writer) io.WriteCloser {
+			return base64.NewEncoder(base64.StdEncoding, w)
+		},
+		encodedLen: base64.StdEncoding.EncodedLen,
+		decode:     base64.StdEncoding.DecodeString,
+	},
+	"hex": {
+		newEncoder: func(w io.Writer) io.WriteCloser {
+			return nopCloser{hex.NewEncoder(w)}
+		},
+		encodedLen: hex.EncodedLen,
+		decode:     hex.DecodeString,
+	},
+}
+
+// encode returns data encoded
+func (e fileEncoding) encode(data []byte) ExtendedString {
+	var encoded strings.Builder
+	encoded.Grow(e.encodedLen(len(data)))
+	encoder := e.newEncoder(&encoded)
+	_, _ = encoder.Write(data)
+	_ = encoder.Close()
+	return ExtendedString(encoded.String())
+}
+
+// encodeFile returns the content of the file fileName encoded with the encoding name (see
+// fileEncodings). The file is encoded as it's read, so only the encoded content is kept in memory,
+// unless the certificates on it have to be checked (see SetCertMinValidity). As with readRawFile,
+// files are only read the first time they're loaded on a render
+func encodeFile(fileName string, name string) (ExtendedString, error) {
+	path, err := resolvePath(fileName)
+	if err != nil {
+		return "", err
+	}
+	return cachedEncoding(path, fileName, name, func() (ExtendedString, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read file %s: %w", fileName, err)
+		}
+		defer func() { _ = file.Close() }()
+
+		var encoded strings.Builder
+		if info, err := file.Stat(); err == nil {
+			encoded.Grow(fileEncodings[name].encodedLen(int(info.Size())))
+		}
+		var reader io.Reader = file
+		var raw bytes.Buffer
+		if certMinValidity != 0 {
+			reader = io.TeeReader(file, &raw)
+		}
+		encoder := fileEncodings[name].newEncoder(&encoded)
+		if _, err := io.Copy(encoder, reader); err != nil {
+			return "", fmt.Errorf("cannot read file %s: %w", fileName, err)
+		}
+		if err := encoder.Close(); err != nil {
+			return "", fmt.Errorf("cannot encode file %s: %v", fileName, err)
+		}
+		if err := checkCertificates(raw.Bytes(), "file "+fileName); err != nil {
+			return "", err
+		}
+		return ExtendedString(encoded.String()), nil
+	})
+}
+
+// nopCloser adds a Close method that does nothing to encoders that do not need it
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetFileRoots(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "inside.txt"), []byte("inside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	if err := os.Symlink(root, filepath.Join(base, "rootlink")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetFileRoots([]string{filepath.Join(base, "missing")}); err == nil {
+		t.Errorf("SetFileRoots() accepted a root that does not exist")
+	}
+	if err := SetFileRoots([]string{filepath.Join(base, "rootlink")}); err != nil {
+		t.Fatalf("SetFileRoots() error = %v", err)
+	}
+	defer func() { _ = SetFileRoots(nil) }()
+
+	tests := []struct {
+		name     string
+		es       ExtendedString
+		basePath string
+		want     ExtendedString
+		wantErr  bool
+	}{
+		{
+			name: "File inside the root",
+			es:   ExtendedString(filepath.Join(root, "inside.txt")),
+			want: "inside",
+		},
+		{
+			name: "File inside the root through a symlinked directory",
+			es:   ExtendedString(filepath.Join(base, "rootlink", "inside.txt")),
+			want: "inside",
+		},
+		{
+			name:    "File outside the root",
+			es:      ExtendedString(filepath.Join(outside, "secret.txt")),
+			wantErr: true,
+		},
+		{
+			name:    "Symlink to a file outside the root",
+			es:      ExtendedString(filepath.Join(root, "link.txt")),
+			wantErr: true,
+		},
+		{
+			name:     "Relative file inside the root",
+			es:       "inside.txt",
+			basePath: root,
+			want:     "inside",
+		},
+		{
+			name:     "Relative file escaping the root",
+			es:       "../outside/secret.txt",
+			basePath: root,
+			wantErr:  true,
+		},
+		{
+			name:     "Relative file that goes out and back in the root",
+			es:       "../root/inside.txt",
+			basePath: root,
+			want:     "inside",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ExtendedString
+			var err error
+			if tt.basePath != "" {
+				got, err = tt.es.LoadRelativeFile(tt.basePath)
+			} else {
+				got, err = tt.es.LoadFile()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load = %q, error = %v, wantErr %v", got, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Load = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadedFile is one of the files returned by LoadGlob or LoadDir
+type LoadedFile struct {
+	// Name is the path of the file, as matched by the LoadGlob pattern, or relative to the LoadDir
+	// directory
+	Name    string
+	Content ExtendedString
+}
+
+// loadOptions are the options LoadGlob and LoadDir accept
+type loadOptions struct {
+	maxSize   int64
+	noHidden  bool
+	recursive bool
+}
+
+// parseLoadOptions parses the options of LoadGlob and LoadDir:
+//   - max-size=SIZE fails if any of the files is bigger than SIZE, a byte size such as 512Ki or 1MB
+//   - no-hidden skips the files and directories whose name starts with a dot
+//   - recursive (only for LoadDir) loads the files on the subdirectories too
+func parseLoadOptions(method string, options []string, allowRecursive bool) (opts loadOptions, err error) {
+	for _, option := range options {
+		name, value, hasValue := strings.Cut(option, "=")
+		switch {
+		case name == "max-size" && hasValue:
+			if opts.maxSize, err = parseByteSize(value); err != nil {
+				return opts, fmt.Errorf("%s: invalid max-size %q: %v", method, value, err)
+			}
+		case name == "no-hidden" && !hasValue:
+			opts.noHidden = true
+		case name == "recursive" && !hasValue && allowRecursive:
+			opts.recursive = true
+		default:
+			return opts, fmt.Errorf("%s: unknown option %q", method, option)
+		}
+	}
+	return opts, nil
+}
+
+// LoadGlob loads all the files that match the pattern stored on es, sorted by name. Besides the
+// filepath.Match syntax, a ** path component matches any number of directories, as in
+// /etc/certs/**/*.pem. The options are max-size=SIZE (to fail if any file is bigger than SIZE)
+// and no-hidden (to skip files and directories starting with a dot). A pattern that does not
+// match any file returns an empty list. As with the other Load methods, the result is the same for
+// the whole render (see ResetFileCache), even if files are added or removed in the middle of it
+func (es ExtendedString) LoadGlob(options ...string) ([]LoadedFile, error) {
+	opts, err := parseLoadOptions("LoadGlob", options, false)
+	if err != nil {
+		return nil, err
+	}
+	return cachedFiles(listingKey("LoadGlob", es, options), func() ([]LoadedFile, error) {
+		return es.loadGlob(opts)
+	})
+}
+
+// loadGlob does the actual work of LoadGlob
+func (es ExtendedString) loadGlob(opts loadOptions) ([]LoadedFile, error) {
+	segments := strings.Split(filepath.ToSlash(string(es)), "/")
+	for _, segment := range segments {
+		if _, err := filepath.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("LoadGlob: invalid pattern %s: %v", es, err)
+		}
+	}
+	// The files are searched from the longest prefix of the pattern without any wildcard
+	baseLen := 0
+	for baseLen < len(segments)-1 && !hasMeta(segments[baseLen]) {
+		baseLen++
+	}
+	base := strings.Join(segments[:baseLen], "/")
+	if base == "" {
+		base = "."
+		if baseLen > 0 {
+			base = "/"
+		}
+	}
+	base = filepath.FromSlash(base)
+	// The base is checked against the file roots first, so templates cannot find out whether a path
+	// outside them exists
+	if _, err := resolvePath(base); err != nil {
+		return nil, fmt.Errorf("LoadGlob: %v", err)
+	}
+	if _, err := os.Stat(base); errors.Is(err, fs.ErrNotExist) {
+		return []LoadedFile{}, nil
+	}
+	files, err := loadFiles(base, segments[baseLen:], opts)
+	if err != nil {
+		return nil, fmt.Errorf("LoadGlob: %v", err)
+	}
+	for i := range files {
+		files[i].Name = filepath.Join(base, files[i].Name)
+	}
+	return files, nil
+}
+
+// LoadDir loads all the files on the directory stored on es, sorted by name (relative to the
+// directory). The options are the ones of LoadGlob, plus recursive to load the files on the
+// subdirectories too. As LoadGlob, it returns the same files for the whole render
+func (es ExtendedString) LoadDir(options ...string) ([]LoadedFile, error) {
+	opts, err := parseLoadOptions("LoadDir", options, true)
+	if err != nil {
+		return nil, err
+	}
+	pattern := "*"
+	if opts.recursive {
+		pattern = "**"
+	}
+	return cachedFiles(listingKey("LoadDir", es, options), func() ([]LoadedFile, error) {
+		files, err := loadFiles(string(es), []string{pattern}, opts)
+		if err != nil {
+			return nil, fmt.Errorf("LoadDir: %v", err)
+		}
+		return files, nil
+	})
+}
+
+// listingKey identifies a LoadGlob or LoadDir call on the file cache
+func listingKey(method string, es ExtendedString, options []string) string {
+	return strings.Join(append([]string{method, string(es)}, options...), "\x00")
+}
+
+// loadFiles walks base and loads the files whose path (relative to base) matches pattern, one
+// path component per pattern segment. Directories and other non regular files are skipped
+func loadFiles(base string, pattern []string, opts loadOptions) ([]LoadedFile, error) {
+	if _, err := resolvePath(base); err != nil {
+		return nil, err
+	}
+	recursive := false
+	for _, segment := range pattern {
+		recursive = recursive || segment == "**"
+	}
+
+	files := []LoadedFile{}
+	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == base {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		if opts.noHidden && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		depth := strings.Count(filepath.ToSlash(rel), "/") + 1
+		if d.IsDir() {
+			if !recursive && depth >= len(pattern) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !matchSegments(pattern, strings.Split(filepath.ToSlash(rel), "/")) {
+			return nil
+		}
+		// Follows symlinks, so the actual file is checked. Broken symlinks are skipped
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if opts.maxSize > 0 && info.Size() > opts.maxSize {
+			return fmt.Errorf("file %s is bigger than the maximum size (%d bytes)", path, opts.maxSize)
+		}
+		content, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, LoadedFile{Name: rel, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// matchSegments returns true if the path components match the pattern segments. Each segment
+// matches a single component (as in filepath.Match), except ** that matches any number of them
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if matched, _ := filepath.Match(pattern[0], path[0]); !matched {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// hasMeta returns true if the pattern segment has any of the filepath.Match special characters
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
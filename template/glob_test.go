@@ -0,0 +1,200 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeTree creates the files (with their name as content) under a temporary directory, and returns
+// the directory
+func makeTree(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func fileNames(files []LoadedFile) []string {
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = filepath.ToSlash(file.Name)
+		if string(file.Content) != names[i] {
+			names[i] += " (wrong content " + string(file.Content) + ")"
+		}
+	}
+	return names
+}
+
+func TestExtendedString_LoadGlob(t *testing.T) {
+	dir := makeTree(t, "a.pem", "b.pem", "c.txt", ".hidden.pem", "sub/d.pem", "sub/deep/e.pem", ".git/f.pem")
+	t.Chdir(dir)
+
+	tests := []struct {
+		name    string
+		pattern ExtendedString
+		options []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "Single directory",
+			pattern: "*.pem",
+			want:    []string{".hidden.pem", "a.pem", "b.pem"},
+		},
+		{
+			name:    "Without hidden files",
+			pattern: "*.pem",
+			options: []string{"no-hidden"},
+			want:    []string{"a.pem", "b.pem"},
+		},
+		{
+			name:    "Recursive",
+			pattern: "**/*.pem",
+			options: []string{"no-hidden"},
+			want:    []string{"a.pem", "b.pem", "sub/d.pem", "sub/deep/e.pem"},
+		},
+		{
+			name:    "Recursive with hidden files",
+			pattern: "**/*.pem",
+			want:    []string{".git/f.pem", ".hidden.pem", "a.pem", "b.pem", "sub/d.pem", "sub/deep/e.pem"},
+		},
+		{
+			name:    "Subdirectory wildcard",
+			pattern: "*/*.pem",
+			options: []string{"no-hidden"},
+			want:    []string{"sub/d.pem"},
+		},
+		{
+			name:    "Fixed prefix",
+			pattern: "sub/**",
+			want:    []string{"sub/d.pem", "sub/deep/e.pem"},
+		},
+		{
+			name:    "No wildcards",
+			pattern: "./c.txt",
+			want:    []string{"c.txt"},
+		},
+		{
+			name:    "No matches",
+			pattern: "missing/*.pem",
+			want:    []string{},
+		},
+		{
+			name:    "Max size",
+			pattern: "*.txt",
+			options: []string{"max-size=1Ki"},
+			want:    []string{"c.txt"},
+		},
+		{
+			name:    "File bigger than max size",
+			pattern: "*.txt",
+			options: []string{"max-size=2"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid pattern",
+			pattern: "[*.pem",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown option",
+			pattern: "*.pem",
+			options: []string{"recursive"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pattern.LoadGlob(tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadGlob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(fileNames(got), tt.want) {
+				t.Errorf("LoadGlob() = %v, want %v", fileNames(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedString_LoadDir(t *testing.T) {
+	dir := makeTree(t, "a.conf", "b.conf", ".hidden", "sub/c.conf")
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	tests := []struct {
+		name    string
+		dir     ExtendedString
+		options []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Files on the directory",
+			dir:  ExtendedString(dir),
+			want: []string{".hidden", "a.conf", "b.conf"},
+		},
+		{
+			name:    "Recursive without hidden files",
+			dir:     ExtendedString(dir),
+			options: []string{"recursive", "no-hidden"},
+			want:    []string{"a.conf", "b.conf", "sub/c.conf"},
+		},
+		{
+			name:    "Missing directory",
+			dir:     ExtendedString(filepath.Join(dir, "missing")),
+			wantErr: true,
+		},
+		{
+			name:    "Invalid max size",
+			dir:     ExtendedString(dir),
+			options: []string{"max-size=lots"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dir.LoadDir(tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(fileNames(got), tt.want) {
+				t.Errorf("LoadDir() = %v, want %v", fileNames(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedString_LoadDirFileRoots(t *testing.T) {
+	dir := makeTree(t, "allowed/a.conf", "other/b.conf")
+	if err := SetFileRoots([]string{filepath.Join(dir, "allowed")}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetFileRoots(nil) }()
+
+	if _, err := ExtendedString(filepath.Join(dir, "allowed")).LoadDir(); err != nil {
+		t.Errorf("LoadDir() error = %v", err)
+	}
+	if got, err := ExtendedString(dir).LoadDir("recursive"); err == nil {
+		t.Errorf("LoadDir() = %v, want an error", fileNames(got))
+	}
+	if got, err := ExtendedString(filepath.Join(dir, "allowed", "..", "other", "*")).LoadGlob(); err == nil {
+		t.Errorf("LoadGlob() = %v, want an error", fileNames(got))
+	}
+	// Missing files outside the roots are denied too, instead of returning an empty list
+	if got, err := ExtendedString(filepath.Join(dir, "missing", "*.conf")).LoadGlob(); err == nil {
+		t.Errorf("LoadGlob() = %v, want an error", fileNames(got))
+	}
+	if got, err := ExtendedString(filepath.Join(dir, "allowed", "missing", "*.conf")).LoadGlob(); err != nil || len(got) > 0 {
+		t.Errorf("LoadGlob() = %v, %v, want an empty list", fileNames(got), err)
+	}
+}
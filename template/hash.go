@@ -0,0 +1,81 @@
+package template
+
+import (
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+)
+
+// SHA256 returns the SHA-256 hash of es, hex encoded
+func (es ExtendedString) SHA256() ExtendedString {
+	sum := sha256.Sum256([]byte(es))
+	return ExtendedString(hex.EncodeToString(sum[:]))
+}
+
+// SHA256Base64 returns the SHA-256 hash of es, base64 (standard encoding) encoded
+func (es ExtendedString) SHA256Base64() ExtendedString {
+	sum := sha256.Sum256([]byte(es))
+	return ExtendedString(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// SHA512 returns the SHA-512 hash of es, hex encoded
+func (es ExtendedString) SHA512() ExtendedString {
+	sum := sha512.Sum512([]byte(es))
+	return ExtendedString(hex.EncodeToString(sum[:]))
+}
+
+// SHA512Base64 returns the SHA-512 hash of es, base64 (standard encoding) encoded
+func (es ExtendedString) SHA512Base64() ExtendedString {
+	sum := sha512.Sum512([]byte(es))
+	return ExtendedString(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// MD5 returns the MD5 hash of es, hex encoded. MD5 is not secure, so this should only be used for
+// checksums
+func (es ExtendedString) MD5() ExtendedString {
+	sum := md5.Sum([]byte(es)) //nolint:gosec
+	return ExtendedString(hex.EncodeToString(sum[:]))
+}
+
+// MD5Base64 returns the MD5 hash of es, base64 (standard encoding) encoded
+func (es ExtendedString) MD5Base64() ExtendedString {
+	sum := md5.Sum([]byte(es)) //nolint:gosec
+	return ExtendedString(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// HMAC returns the HMAC-SHA256 of es using key as the key, hex encoded
+func (es ExtendedString) HMAC(key ExtendedString) ExtendedString {
+	return ExtendedString(hex.EncodeToString(es.hmac(key)))
+}
+
+// HMACBase64 returns the HMAC-SHA256 of es using key as the key, base64 (standard encoding)
+// encoded
+func (es ExtendedString) HMACBase64(key ExtendedString) ExtendedString {
+	return ExtendedString(base64.StdEncoding.EncodeToString(es.hmac(key)))
+}
+
+func (es ExtendedString) hmac(key ExtendedString) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(es))
+	return mac.Sum(nil)
+}
+
+// CRC32 returns the CRC-32 (IEEE) checksum of es, as 8 hex digits
+func (es ExtendedString) CRC32() ExtendedString {
+	return ExtendedString(hex.EncodeToString(es.crc32()))
+}
+
+// CRC32Base64 returns the CRC-32 (IEEE) checksum of es, base64 (standard encoding) encoded
+func (es ExtendedString) CRC32Base64() ExtendedString {
+	return ExtendedString(base64.StdEncoding.EncodeToString(es.crc32()))
+}
+
+// crc32 returns the checksum of es as big endian bytes
+func (es ExtendedString) crc32() []byte {
+	return binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE([]byte(es)))
+}
@@ -0,0 +1,30 @@
+package template
+
+import "testing"
+
+func TestExtendedString_Hashes(t *testing.T) {
+	es := ExtendedString("abcd1234")
+	tests := []struct {
+		name string
+		got  ExtendedString
+		want ExtendedString
+	}{
+		{"SHA256", es.SHA256(), "e9cee71ab932fde863338d08be4de9dfe39ea049bdafb342ce659ec5450b69ae"},
+		{"SHA256Base64", es.SHA256Base64(), "6c7nGrky/ehjM40Ivk3p3+OeoEm9r7NCzmWexUULaa4="},
+		{"SHA512", es.SHA512(), "925f43c3cfb956bbe3c6aa8023ba7ad5cfa21d104186fffc69e768e55940d9653b1cd36fba614fba2e1844f4436da20f83750c6ec1db356da154691bdd71a9b1"},
+		{"SHA512Base64", es.SHA512Base64(), "kl9Dw8+5VrvjxqqAI7p61c+iHRBBhv/8aedo5VlA2WU7HNNvumFPui4YRPRDbaIPg3UMbsHbNW2hVGkb3XGpsQ=="},
+		{"MD5", es.MD5(), "e19d5cd5af0378da05f63f891c7467af"},
+		{"MD5Base64", es.MD5Base64(), "4Z1c1a8DeNoF9j+JHHRnrw=="},
+		{"HMAC", es.HMAC("key"), "c586a61c60196618f3db2690e34fb505bdc1c69b5fc749a4a80340605953685a"},
+		{"HMACBase64", es.HMACBase64("key"), "xYamHGAZZhjz2yaQ40+1Bb3Bxptfx0mkqANAYFlTaFo="},
+		{"CRC32", es.CRC32(), "3d3fb146"},
+		{"CRC32Base64", es.CRC32Base64(), "PT+xRg=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package template
+
+import (
+	"envtemplate/utils"
+	"fmt"
+	"strings"
+)
+
+// source describes where es comes from, for error messages: the file it was loaded from, if it was
+// returned by any of the Load methods during the current render (see cachedFileName)
+func (es ExtendedString) source() string {
+	if fileName, ok := cachedFileName(es); ok {
+		return fmt.Sprintf("file %s", fileName)
+	}
+	return "value"
+}
+
+// ParseJSON parses es as a JSON document, and returns the resulting value so it can be navigated
+// from the template, as in {[(.FILE.LoadFile.ParseJSON).key]}. Objects are returned as maps, arrays
+// as slices, and numbers as json.Number
+func (es ExtendedString) ParseJSON() (any, error) {
+	return es.parse(utils.FormatJSON)
+}
+
+// ParseYAML parses es as a YAML document, and returns the resulting value so it can be navigated
+// from the template
+func (es ExtendedString) ParseYAML() (any, error) {
+	return es.parse(utils.FormatYAML)
+}
+
+// ParseTOML parses es as a TOML document, and returns the resulting table so it can be navigated
+// from the template
+func (es ExtendedString) ParseTOML() (any, error) {
+	return es.parse(utils.FormatTOML)
+}
+
+func (es ExtendedString) parse(format string) (any, error) {
+	rv, err := utils.DecodeStructured(format, []byte(es))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s as %s: %v", es.source(), strings.ToUpper(format), err)
+	}
+	return rv, nil
+}
@@ -0,0 +1,79 @@
+package template
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mustLoad returns the content of fileName, ignoring errors
+func mustLoad(fileName string) ExtendedString {
+	content, _ := ExtendedString(fileName).LoadFile()
+	return content
+}
+
+func TestExtendedString_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(ExtendedString) (any, error)
+		es      ExtendedString
+		want    any
+		wantErr string
+	}{
+		{
+			name:  "JSON file",
+			parse: ExtendedString.ParseJSON,
+			es:    mustLoad("./test/sample.json"),
+			want:  map[string]any{"key": "value", "list": []any{json.Number("1"), json.Number("2")}},
+		},
+		{
+			name:  "JSON value",
+			parse: ExtendedString.ParseJSON,
+			es:    `["a", {"b": true}]`,
+			want:  []any{"a", map[string]any{"b": true}},
+		},
+		{
+			name:  "YAML file",
+			parse: ExtendedString.ParseYAML,
+			es:    mustLoad("./test/sample.yaml"),
+			want:  map[string]any{"key": "value", "list": []any{1, 2}},
+		},
+		{
+			name:  "TOML file",
+			parse: ExtendedString.ParseTOML,
+			es:    mustLoad("./test/sample.toml"),
+			want:  map[string]any{"key": "value", "list": []any{int64(1), int64(2)}},
+		},
+		{
+			name:    "Invalid JSON file",
+			parse:   ExtendedString.ParseJSON,
+			es:      mustLoad("./test/broken.json"),
+			wantErr: "cannot parse file ./test/broken.json as JSON",
+		},
+		{
+			name:    "Invalid YAML value",
+			parse:   ExtendedString.ParseYAML,
+			es:      "key: [",
+			wantErr: "cannot parse value as YAML",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.es)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Parse() unexpected error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package template
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ShellQuote returns es single quoted for POSIX shells, so it's used literally: nothing (not even
+// $, backquotes or new lines) is interpreted inside. A single quote on es ends the quoting, is
+// written escaped (as \') and then the quoting starts again
+func (es ExtendedString) ShellQuote() ExtendedString {
+	return ExtendedString("'" + strings.ReplaceAll(string(es), "'", `'\''`) + "'")
+}
+
+// SystemdEscape returns es double quoted to be used as a value in a systemd EnvironmentFile. \, ",
+// $ and backquotes are escaped. New lines are kept, since systemd allows them in quoted values
+func (es ExtendedString) SystemdEscape() ExtendedString {
+	return ExtendedString(`"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(string(es)) + `"`)
+}
+
+// DotenvQuote returns es double quoted as a dotenv value, as written by ToEnv. \, ", $ and new
+// lines are escaped, so the value can be read back exactly
+func (es ExtendedString) DotenvQuote() ExtendedString {
+	return ExtendedString(dotenvQuote(string(es)))
+}
+
+// XMLEscape escapes es so it can be used as XML text or as an attribute value
+func (es ExtendedString) XMLEscape() ExtendedString {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(es))
+	return ExtendedString(buf.String())
+}
+
+// Escapers maps the name of each escaping function (as added by FuncMap) to the ExtendedString
+// method it calls
+var Escapers = map[string]string{
+	"shellQuote":    "ShellQuote",
+	"systemdEscape": "SystemdEscape",
+	"dotenvQuote":   "DotenvQuote",
+	"xmlEscape":     "XMLEscape",
+}
+
+// escaperFunc returns a function that escapes any template value (converted to a string as the
+// template would print it) with the escape method
+func escaperFunc(escape func(ExtendedString) ExtendedString) func(any) ExtendedString {
+	return func(value any) ExtendedString {
+		if value == nil {
+			return escape("")
+		}
+		return escape(ExtendedString(fmt.Sprint(value)))
+	}
+}
@@ -0,0 +1,69 @@
+package template
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestExtendedString_Quoting(t *testing.T) {
+	tests := []struct {
+		name        string
+		es          ExtendedString
+		wantShell   ExtendedString
+		wantSystemd ExtendedString
+		wantDotenv  ExtendedString
+		wantXML     ExtendedString
+	}{
+		{
+			name:        "Simple string",
+			es:          "abcd1234",
+			wantShell:   "'abcd1234'",
+			wantSystemd: `"abcd1234"`,
+			wantDotenv:  `"abcd1234"`,
+			wantXML:     "abcd1234",
+		},
+		{
+			name:        "Quotes and expansions",
+			es:          "it's \"$HOME\" `id` <&>",
+			wantShell:   `'it'\''s "$HOME" ` + "`id`" + ` <&>'`,
+			wantSystemd: `"it's \"\$HOME\" \` + "`id\\`" + ` <&>"`,
+			wantDotenv:  `"it's \"\$HOME\" ` + "`id`" + ` <&>"`,
+			wantXML:     "it&#39;s &#34;$HOME&#34; `id` &lt;&amp;&gt;",
+		},
+		{
+			name:        "Multi line string",
+			es:          "a\\b\nc",
+			wantShell:   "'a\\b\nc'",
+			wantSystemd: "\"a\\\\b\nc\"",
+			wantDotenv:  `"a\\b\nc"`,
+			wantXML:     "a\\b&#xA;c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.es.ShellQuote(); got != tt.wantShell {
+				t.Errorf("ShellQuote() = %q, want %q", got, tt.wantShell)
+			}
+			if got := tt.es.SystemdEscape(); got != tt.wantSystemd {
+				t.Errorf("SystemdEscape() = %q, want %q", got, tt.wantSystemd)
+			}
+			if got := tt.es.DotenvQuote(); got != tt.wantDotenv {
+				t.Errorf("DotenvQuote() = %q, want %q", got, tt.wantDotenv)
+			}
+			if got := tt.es.XMLEscape(); got != tt.wantXML {
+				t.Errorf("XMLEscape() = %q, want %q", got, tt.wantXML)
+			}
+		})
+	}
+}
+
+func TestExtendedString_ShellQuoteRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell available")
+	}
+	es := ExtendedString("it's \"$HOME\" `id` \\ $(x)\nnew line")
+	out, err := exec.Command("sh", "-c", "printf '%s' "+string(es.ShellQuote())).Output()
+	if err != nil || string(out) != string(es) {
+		t.Errorf("ShellQuote() does not round trip: %q, %v", out, err)
+	}
+}
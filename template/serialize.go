@@ -0,0 +1,298 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FuncMap returns the functions that serialize or escape any template value (strings,
+// TemplateData, or the structures returned by the Parse methods), to be added to the templates
+// function map
+func FuncMap() map[string]any {
+	return map[string]any{
+		"toYAML":        EncodeYAML,
+		"toTOML":        EncodeTOML,
+		"toHCL":         EncodeHCL,
+		"toEnv":         EncodeEnv,
+		"shellQuote":    escaperFunc(ExtendedString.ShellQuote),
+		"systemdEscape": escaperFunc(ExtendedString.SystemdEscape),
+		"dotenvQuote":   escaperFunc(ExtendedString.DotenvQuote),
+		"xmlEscape":     escaperFunc(ExtendedString.XMLEscape),
+	}
+}
+
+// ToYAML returns es as a YAML scalar. Multi line strings are returned as literal blocks
+func (es ExtendedString) ToYAML() (ExtendedString, error) {
+	return EncodeYAML(es)
+}
+
+// ToTOML returns es as a TOML string, quoted and escaped
+func (es ExtendedString) ToTOML() (ExtendedString, error) {
+	return EncodeTOML(es)
+}
+
+// ToHCL returns es as an HCL string, quoted and escaped. Template sequences (${ and %{) are escaped
+// too, so they are not interpolated
+func (es ExtendedString) ToHCL() ExtendedString {
+	return ExtendedString(hclQuote(string(es)))
+}
+
+// ToEnv returns es as a double quoted dotenv value
+func (es ExtendedString) ToEnv() ExtendedString {
+	return ExtendedString(dotenvQuote(string(es)))
+}
+
+// EncodeYAML returns value as a YAML document (or a YAML scalar if value is a single value), without
+// the trailing new line
+func EncodeYAML(value any) (ExtendedString, error) {
+	data, err := yaml.Marshal(normalize(value))
+	if err != nil {
+		return "", fmt.Errorf("cannot encode as YAML: %v", err)
+	}
+	return ExtendedString(strings.TrimSuffix(string(data), "\n")), nil
+}
+
+// EncodeTOML returns value as a TOML document if it's a map, or as a TOML value otherwise
+func EncodeTOML(value any) (ExtendedString, error) {
+	value = normalize(value)
+	isTable := false
+	if rv := reflect.ValueOf(value); rv.IsValid() && rv.Kind() == reflect.Map {
+		isTable = true
+	} else {
+		// TOML documents must be tables, so we encode single values as a key and remove the key
+		value = map[string]any{"v": value}
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(value); err != nil {
+		return "", fmt.Errorf("cannot encode as TOML: %v", err)
+	}
+	if isTable {
+		return ExtendedString(buf.String()), nil
+	}
+	encoded, found := strings.CutPrefix(buf.String(), "v = ")
+	if !found {
+		return "", fmt.Errorf("cannot encode %T as a TOML value", value)
+	}
+	return ExtendedString(strings.TrimSuffix(encoded, "\n")), nil
+}
+
+// EncodeHCL returns value as an HCL expression: strings are quoted and escaped, slices are
+// returned as tuples and maps as objects
+func EncodeHCL(value any) (ExtendedString, error) {
+	var buf strings.Builder
+	if err := writeHCL(&buf, reflect.ValueOf(normalize(value)), ""); err != nil {
+		return "", err
+	}
+	return ExtendedString(buf.String()), nil
+}
+
+// EncodeHCLAttributes returns the map value as a list of HCL attributes (key = value), sorted by key
+func EncodeHCLAttributes(value any) (ExtendedString, error) {
+	rv := indirect(reflect.ValueOf(normalize(value)))
+	if !rv.IsValid() || rv.Kind() != reflect.Map {
+		return "", fmt.Errorf("cannot encode %T as HCL attributes: it's not a map", value)
+	}
+	var buf strings.Builder
+	for _, key := range sortedKeys(rv) {
+		buf.WriteString(hclKey(key))
+		buf.WriteString(" = ")
+		if err := writeHCL(&buf, rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())), ""); err != nil {
+			return "", err
+		}
+		buf.WriteString("\n")
+	}
+	return ExtendedString(buf.String()), nil
+}
+
+// EncodeEnv returns value as dotenv lines (NAME="value", sorted by name) if it's a map, or as a
+// double quoted dotenv value otherwise. Only single values (strings, numbers and booleans) can be
+// encoded
+func EncodeEnv(value any) (ExtendedString, error) {
+	rv := indirect(reflect.ValueOf(normalize(value)))
+	if !rv.IsValid() || rv.Kind() != reflect.Map {
+		quoted, err := envValue(rv)
+		return ExtendedString(quoted), err
+	}
+	var buf strings.Builder
+	for _, key := range sortedKeys(rv) {
+		quoted, err := envValue(indirect(rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))))
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", key, err)
+		}
+		buf.WriteString(key)
+		buf.WriteString("=")
+		buf.WriteString(quoted)
+		buf.WriteString("\n")
+	}
+	return ExtendedString(buf.String()), nil
+}
+
+func envValue(rv reflect.Value) (string, error) {
+	if !rv.IsValid() {
+		return `""`, nil
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return dotenvQuote(fmt.Sprint(rv.Interface())), nil
+	default:
+		return "", fmt.Errorf("cannot encode %s as a dotenv value", rv.Type())
+	}
+}
+
+// dotenvQuote double quotes s, escaping it so it can be read back from a dotenv file
+func dotenvQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`).Replace(s) + `"`
+}
+
+// hclQuote quotes and escapes s as an HCL string literal
+func hclQuote(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// ${ and %{ start template sequences, $${ and %%{ are their literal versions
+			buf.WriteRune(r)
+			buf.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			buf.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+var hclIdentifierRexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// hclKey returns key as it can be written as an HCL object key or attribute name
+func hclKey(key string) string {
+	if hclIdentifierRexp.MatchString(key) {
+		return key
+	}
+	return hclQuote(key)
+}
+
+func writeHCL(buf *strings.Builder, rv reflect.Value, indent string) error {
+	rv = indirect(rv)
+	if !rv.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		buf.WriteString(hclQuote(rv.String()))
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(rv.Float(), 'g', -1, 64))
+	case reflect.Slice, reflect.Array:
+		buf.WriteString("[")
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeHCL(buf, rv.Index(i), indent); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+	case reflect.Map:
+		keys := sortedKeys(rv)
+		if len(keys) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for _, key := range keys {
+			buf.WriteString(indent + "  " + hclKey(key) + " = ")
+			if err := writeHCL(buf, rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())), indent+"  "); err != nil {
+				return err
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	default:
+		return fmt.Errorf("cannot encode %s as HCL", rv.Type())
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the map rv, which must be strings, sorted
+func sortedKeys(rv reflect.Value) []string {
+	keys := make([]string, 0, rv.Len())
+	for _, key := range rv.MapKeys() {
+		keys = append(keys, fmt.Sprint(key.Interface()))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalize converts the values that the encoders would not handle as expected (json.Number, and
+// maps with any as key, as returned by the Parse methods) into equivalent ones
+func normalize(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return string(v)
+	case map[string]any:
+		rv := make(map[string]any, len(v))
+		for k, e := range v {
+			rv[k] = normalize(e)
+		}
+		return rv
+	case map[any]any:
+		rv := make(map[string]any, len(v))
+		for k, e := range v {
+			rv[fmt.Sprint(k)] = normalize(e)
+		}
+		return rv
+	case []any:
+		rv := make([]any, len(v))
+		for i, e := range v {
+			rv[i] = normalize(e)
+		}
+		return rv
+	}
+	return value
+}
+
+// indirect returns the value pointed to or wrapped by rv
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
@@ -0,0 +1,96 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtendedString_Serialization(t *testing.T) {
+	tests := []struct {
+		name     string
+		es       ExtendedString
+		wantYAML ExtendedString
+		wantTOML ExtendedString
+		wantHCL  ExtendedString
+		wantEnv  ExtendedString
+	}{
+		{
+			name:     "Simple string",
+			es:       "abcd1234",
+			wantYAML: "abcd1234",
+			wantTOML: `"abcd1234"`,
+			wantHCL:  `"abcd1234"`,
+			wantEnv:  `"abcd1234"`,
+		},
+		{
+			name:     "String that looks like a number",
+			es:       "0123",
+			wantYAML: `"0123"`,
+			wantTOML: `"0123"`,
+			wantHCL:  `"0123"`,
+			wantEnv:  `"0123"`,
+		},
+		{
+			name:     "Quotes and templates",
+			es:       `say "hi" to ${USER} and %{if x}\`,
+			wantYAML: `say "hi" to ${USER} and %{if x}\`,
+			wantTOML: `"say \"hi\" to ${USER} and %{if x}\\"`,
+			wantHCL:  `"say \"hi\" to $${USER} and %%{if x}\\"`,
+			wantEnv:  `"say \"hi\" to \${USER} and %{if x}\\"`,
+		},
+		{
+			name:     "Multi line string",
+			es:       "line 1\nline 2",
+			wantYAML: "|-\n    line 1\n    line 2",
+			wantTOML: `"line 1\nline 2"`,
+			wantHCL:  `"line 1\nline 2"`,
+			wantEnv:  `"line 1\nline 2"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.es.ToYAML(); err != nil || got != tt.wantYAML {
+				t.Errorf("ToYAML() = %q, %v, want %q", got, err, tt.wantYAML)
+			}
+			if got, err := tt.es.ToTOML(); err != nil || got != tt.wantTOML {
+				t.Errorf("ToTOML() = %q, %v, want %q", got, err, tt.wantTOML)
+			}
+			if got := tt.es.ToHCL(); got != tt.wantHCL {
+				t.Errorf("ToHCL() = %q, want %q", got, tt.wantHCL)
+			}
+			if got := tt.es.ToEnv(); got != tt.wantEnv {
+				t.Errorf("ToEnv() = %q, want %q", got, tt.wantEnv)
+			}
+		})
+	}
+}
+
+func TestEncodeStructures(t *testing.T) {
+	value := map[string]any{
+		"name":    ExtendedString("svc"),
+		"port":    json.Number("8080"),
+		"servers": []any{"a", "b"},
+		"tls":     map[string]any{"enabled": true},
+	}
+	if got, err := EncodeYAML(value); err != nil || got != "name: svc\nport: 8080\nservers:\n    - a\n    - b\ntls:\n    enabled: true" {
+		t.Errorf("EncodeYAML() = %q, %v", got, err)
+	}
+	if got, err := EncodeTOML(value); err != nil || got != "name = \"svc\"\nport = 8080\nservers = [\"a\", \"b\"]\n\n[tls]\n  enabled = true\n" {
+		t.Errorf("EncodeTOML() = %q, %v", got, err)
+	}
+	if got, err := EncodeTOML([]any{1, "a"}); err != nil || got != `[1, "a"]` {
+		t.Errorf("EncodeTOML() = %q, %v", got, err)
+	}
+	if got, err := EncodeHCL(value); err != nil || got != "{\n  name = \"svc\"\n  port = 8080\n  servers = [\"a\", \"b\"]\n  tls = {\n    enabled = true\n  }\n}" {
+		t.Errorf("EncodeHCL() = %q, %v", got, err)
+	}
+	if got, err := EncodeHCLAttributes(map[string]ExtendedString{"b": "2", "a b": "1"}); err != nil || got != "\"a b\" = \"1\"\nb = \"2\"\n" {
+		t.Errorf("EncodeHCLAttributes() = %q, %v", got, err)
+	}
+	if got, err := EncodeEnv(map[string]any{"B": 2, "A": "x\ny"}); err != nil || got != "A=\"x\\ny\"\nB=\"2\"\n" {
+		t.Errorf("EncodeEnv() = %q, %v", got, err)
+	}
+	if _, err := EncodeEnv(value); err == nil {
+		t.Errorf("EncodeEnv() should fail with nested values")
+	}
+}
@@ -0,0 +1,167 @@
+package template
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bool returns es as a boolean. Besides the values accepted by strconv.ParseBool (1, t, true, 0,
+// f, false...), yes/no, y/n and on/off are accepted too, in any case. It returns an error for any
+// other value, including an empty string
+func (es ExtendedString) Bool() (bool, error) {
+	value := strings.TrimSpace(string(es))
+	switch strings.ToLower(value) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	rv, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value: %q", string(es))
+	}
+	return rv, nil
+}
+
+// BoolOrDefault works as Bool, but it returns def if es is empty
+func (es ExtendedString) BoolOrDefault(def bool) (bool, error) {
+	if es.isBlank() {
+		return def, nil
+	}
+	return es.Bool()
+}
+
+// Int returns es as an integer. Hexadecimal (0x), octal (0o) and binary (0b) prefixes, and _ as a
+// digit separator, are accepted. Numbers without a prefix are always decimal, even if they have
+// leading zeros (so 010 is 10, and not 8 as in Go)
+func (es ExtendedString) Int() (int, error) {
+	value := strings.TrimSpace(string(es))
+	sign := ""
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		sign, value = value[:1], value[1:]
+	}
+	if len(value) > 1 && value[0] == '0' && !strings.ContainsAny(value[1:2], "xXoObB") {
+		// ParseInt would take the leading zero as the octal prefix
+		if value = strings.TrimLeft(value, "0"); len(value) == 0 {
+			value = "0"
+		}
+	}
+	rv, err := strconv.ParseInt(sign+value, 0, strconv.IntSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer value: %q", string(es))
+	}
+	return int(rv), nil
+}
+
+// IntOrDefault works as Int, but it returns def if es is empty
+func (es ExtendedString) IntOrDefault(def int) (int, error) {
+	if es.isBlank() {
+		return def, nil
+	}
+	return es.Int()
+}
+
+// Float returns es as a floating point number
+func (es ExtendedString) Float() (float64, error) {
+	rv, err := strconv.ParseFloat(strings.TrimSpace(string(es)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float value: %q", string(es))
+	}
+	return rv, nil
+}
+
+// FloatOrDefault works as Float, but it returns def if es is empty
+func (es ExtendedString) FloatOrDefault(def float64) (float64, error) {
+	if es.isBlank() {
+		return def, nil
+	}
+	return es.Float()
+}
+
+// Duration returns es as a time.Duration, using the time.ParseDuration format (as in 1h30m or 250ms)
+func (es ExtendedString) Duration() (time.Duration, error) {
+	rv, err := time.ParseDuration(strings.TrimSpace(string(es)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration value: %q", string(es))
+	}
+	return rv, nil
+}
+
+// DurationOrDefault works as Duration, but it returns def (which is also parsed as a duration) if es
+// is empty
+func (es ExtendedString) DurationOrDefault(def string) (time.Duration, error) {
+	if es.isBlank() {
+		return ExtendedString(def).Duration()
+	}
+	return es.Duration()
+}
+
+// Bytes returns the number of bytes es represents. es is a number, optionally followed by a
+// decimal (K, M, G, T, P, E, optionally followed by B) or binary (Ki, Mi, Gi, Ti, Pi, Ei, optionally
+// followed by B) unit, as in 512Mi, 1GB or 1.5G. Units are not case-sensitive, and a plain number
+// (or one followed by B) is a number of bytes
+func (es ExtendedString) Bytes() (int64, error) {
+	rv, err := parseByteSize(string(es))
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size value: %q", string(es))
+	}
+	return rv, nil
+}
+
+// BytesOrDefault works as Bytes, but it returns def (which is also parsed as a byte size) if es is
+// empty
+func (es ExtendedString) BytesOrDefault(def string) (int64, error) {
+	if es.isBlank() {
+		return ExtendedString(def).Bytes()
+	}
+	return es.Bytes()
+}
+
+func (es ExtendedString) isBlank() bool {
+	return len(strings.TrimSpace(string(es))) == 0
+}
+
+// byteUnits holds the multiplier of each byte size unit, without the optional trailing B
+var byteUnits = map[string]float64{
+	"":   1,
+	"k":  1e3,
+	"m":  1e6,
+	"g":  1e9,
+	"t":  1e12,
+	"p":  1e15,
+	"e":  1e18,
+	"ki": 1 << 10,
+	"mi": 1 << 20,
+	"gi": 1 << 30,
+	"ti": 1 << 40,
+	"pi": 1 << 50,
+	"ei": 1 << 60,
+}
+
+// parseByteSize parses a byte size as described on ExtendedString.Bytes
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	numberEnd := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if numberEnd < 0 {
+		numberEnd = len(s)
+	}
+	number, err := strconv.ParseFloat(s[:numberEnd], 64)
+	if err != nil {
+		return 0, err
+	}
+	unit := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s[numberEnd:])), "b")
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %s", s[numberEnd:])
+	}
+	size := math.Round(number * multiplier)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size too big: %s", s)
+	}
+	return int64(size), nil
+}
@@ -0,0 +1,159 @@
+package template
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtendedString_Bool(t *testing.T) {
+	tests := []struct {
+		es      ExtendedString
+		want    bool
+		wantErr bool
+	}{
+		{es: "true", want: true},
+		{es: "1", want: true},
+		{es: " Yes ", want: true},
+		{es: "on", want: true},
+		{es: "false", want: false},
+		{es: "0", want: false},
+		{es: "OFF", want: false},
+		{es: "n", want: false},
+		{es: "", wantErr: true},
+		{es: "maybe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.es), func(t *testing.T) {
+			got, err := tt.es.Bool()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Bool() = %v, %v, want %v, error: %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtendedString_Int(t *testing.T) {
+	tests := []struct {
+		es      ExtendedString
+		want    int
+		wantErr bool
+	}{
+		{es: "42", want: 42},
+		{es: " -7 ", want: -7},
+		{es: "0x1F", want: 31},
+		{es: "1_000", want: 1000},
+		{es: "010", want: 10},
+		{es: "08", want: 8},
+		{es: "-0012", want: -12},
+		{es: "000", want: 0},
+		{es: "0o17", want: 15},
+		{es: "-0b101", want: -5},
+		{es: "0_1", wantErr: true},
+		{es: "1.5", wantErr: true},
+		{es: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.es), func(t *testing.T) {
+			got, err := tt.es.Int()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Int() = %v, %v, want %v, error: %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtendedString_Float(t *testing.T) {
+	tests := []struct {
+		es      ExtendedString
+		want    float64
+		wantErr bool
+	}{
+		{es: "1.5", want: 1.5},
+		{es: "-2", want: -2},
+		{es: "1e3", want: 1000},
+		{es: "one", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.es), func(t *testing.T) {
+			got, err := tt.es.Float()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Float() = %v, %v, want %v, error: %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtendedString_Duration(t *testing.T) {
+	tests := []struct {
+		es      ExtendedString
+		want    time.Duration
+		wantErr bool
+	}{
+		{es: "1h30m", want: 90 * time.Minute},
+		{es: "250ms", want: 250 * time.Millisecond},
+		{es: "10", wantErr: true},
+		{es: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.es), func(t *testing.T) {
+			got, err := tt.es.Duration()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Duration() = %v, %v, want %v, error: %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtendedString_Bytes(t *testing.T) {
+	tests := []struct {
+		es      ExtendedString
+		want    int64
+		wantErr bool
+	}{
+		{es: "100", want: 100},
+		{es: "100B", want: 100},
+		{es: "512Mi", want: 512 << 20},
+		{es: "512MiB", want: 512 << 20},
+		{es: "1GB", want: 1e9},
+		{es: "1.5G", want: 1.5e9},
+		{es: "2 ki", want: 2048},
+		{es: "1Ei", want: 1 << 60},
+		{es: "10Ei", wantErr: true},
+		{es: "1Xi", wantErr: true},
+		{es: "Mi", wantErr: true},
+		{es: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.es), func(t *testing.T) {
+			got, err := tt.es.Bytes()
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Bytes() = %v, %v, want %v, error: %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtendedString_OrDefault(t *testing.T) {
+	var empty ExtendedString
+	if got, err := empty.BoolOrDefault(true); err != nil || !got {
+		t.Errorf("BoolOrDefault() = %v, %v", got, err)
+	}
+	if got, err := ExtendedString("false").BoolOrDefault(true); err != nil || got {
+		t.Errorf("BoolOrDefault() = %v, %v", got, err)
+	}
+	if _, err := ExtendedString("nope").BoolOrDefault(true); err == nil {
+		t.Errorf("BoolOrDefault() should fail on malformed values")
+	}
+	if got, err := empty.IntOrDefault(3); err != nil || got != 3 {
+		t.Errorf("IntOrDefault() = %v, %v", got, err)
+	}
+	if got, err := empty.FloatOrDefault(0.5); err != nil || got != 0.5 {
+		t.Errorf("FloatOrDefault() = %v, %v", got, err)
+	}
+	if got, err := empty.DurationOrDefault("5s"); err != nil || got != 5*time.Second {
+		t.Errorf("DurationOrDefault() = %v, %v", got, err)
+	}
+	if got, err := empty.BytesOrDefault("1Ki"); err != nil || got != 1024 {
+		t.Errorf("BytesOrDefault() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported structured data formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// FormatFromPath returns the structured data format of fileName, based on its extension
+func FormatFromPath(fileName string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(fileName)); ext {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unknown data format for %s. Supported extensions are .json, .yaml, .yml and .toml", fileName)
+	}
+}
+
+// DecodeStructured parses data, written in format, into generic values: maps with string keys,
+// slices, strings, numbers, booleans and nil. JSON numbers are kept as json.Number so integers
+// don't lose precision.
+func DecodeStructured(format string, data []byte) (rv any, err error) {
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err = decoder.Decode(&rv); err == nil && decoder.More() {
+			err = fmt.Errorf("unexpected content after the JSON value")
+		}
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &rv)
+	case FormatTOML:
+		var table map[string]any
+		err = toml.Unmarshal(data, &table)
+		rv = table
+	default:
+		err = fmt.Errorf("unknown data format: %s", format)
+	}
+	return
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{"data.json", FormatJSON, false},
+		{"/some/dir/data.YML", FormatYAML, false},
+		{"data.yaml", FormatYAML, false},
+		{"data.toml", FormatTOML, false},
+		{"data.txt", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			got, err := FormatFromPath(tt.fileName)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("FormatFromPath() = %v, %v, want %v, error: %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeStructured(t *testing.T) {
+	want := map[string]any{
+		"name":    "svc",
+		"servers": []any{"a", "b"},
+	}
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		want    any
+		wantErr bool
+	}{
+		{
+			name:   "JSON",
+			format: FormatJSON,
+			data:   `{"name": "svc", "servers": ["a", "b"]}`,
+			want:   want,
+		},
+		{
+			name:   "JSON numbers",
+			format: FormatJSON,
+			data:   `[12345678901234567890]`,
+			want:   []any{json.Number("12345678901234567890")},
+		},
+		{
+			name:   "YAML",
+			format: FormatYAML,
+			data:   "name: svc\nservers:\n  - a\n  - b\n",
+			want:   want,
+		},
+		{
+			name:   "TOML",
+			format: FormatTOML,
+			data:   "name = \"svc\"\nservers = [\"a\", \"b\"]\n",
+			want:   want,
+		},
+		{
+			name:    "Invalid JSON",
+			format:  FormatJSON,
+			data:    `{"name": }`,
+			wantErr: true,
+		},
+		{
+			name:    "Trailing JSON",
+			format:  FormatJSON,
+			data:    `{} {}`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown format",
+			format:  "xml",
+			data:    `<a/>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeStructured(tt.format, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeStructured() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeStructured() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
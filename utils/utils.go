@@ -104,3 +104,21 @@ func DefineCommandLineFlags(options any, defaults any) (err error) {
 	return
 
 }
+
+// StringList is a flag.Value that accumulates the values of a flag that can be repeated on the
+// command line
+type StringList []string
+
+// String returns the accumulated values, comma separated
+func (sl *StringList) String() string {
+	if sl == nil {
+		return ""
+	}
+	return strings.Join(*sl, ",")
+}
+
+// Set adds value to the list
+func (sl *StringList) Set(value string) error {
+	*sl = append(*sl, value)
+	return nil
+}
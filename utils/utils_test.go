@@ -74,3 +74,15 @@ func TestDefineCommandLineFlags(t *testing.T) {
 		t.Errorf("Second set of flags failure. Expected: %+v, Got: %+v", expectedFlags2, testFlags2)
 	}
 }
+
+func TestStringList(t *testing.T) {
+	testFlags := struct {
+		List StringList `flag:"item;This is a repeatable param"`
+	}{}
+	_ = DefineCommandLineFlags(&testFlags, nil)
+	_ = flag.CommandLine.Parse([]string{"-item", "a", "-item", "b,c"})
+
+	if want := (StringList{"a", "b,c"}); !reflect.DeepEqual(testFlags.List, want) {
+		t.Errorf("StringList failure. Expected: %+v, Got: %+v", want, testFlags.List)
+	}
+}